@@ -0,0 +1,308 @@
+package main
+
+import (
+	"boralabs/internal/chain"
+	"boralabs/internal/event_logger"
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	"boralabs/pkg/router"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+// command Subcommand of the binary, run wires up only the dependencies it needs.
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"serve", "Serve the REST API, with -index the indexer runs in the same process", serve},
+	{"index", "Collect the governor and token events every 30 seconds", index},
+	{"migrate", "Create the MongoDB collections and indexes", migrate},
+	{"reindex", "Collect the events of a block range again and exit", reindex},
+	{"rebuild", "Rebuild the proposals/votes/delegation collections from the event log archive and exit", rebuild},
+	{"verify", "Check MongoDB, the RPC endpoints, the contracts and the stored vote tallies", verify},
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.description)
+	}
+}
+
+// connectChain Load the contracts, with watch the RPC endpoints are health checked in the background until the context is done.
+func connectChain(ctx context.Context, watch bool) error {
+	if err := chain.New(); err != nil {
+		return err
+	}
+	if watch {
+		go chain.Pool.HealthCheck(ctx)
+	}
+	return nil
+}
+
+func serve(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	withMigrate := fs.Bool("migrate", false, "Create the collections and indexes before starting")
+	withIndexer := fs.Bool("index", false, "Run the indexer in the same process")
+	rewind := fs.String("rewind", "", "Rewind the collector checkpoints before indexing, e.g. ProposalCreated=1200,VoteCast=1200")
+	_ = fs.Parse(args)
+
+	mongodb.New()
+	defer mongodb.Disconnect()
+	if *withMigrate {
+		if err := mongodb.Migrate(ctx); err != nil {
+			return err
+		}
+	}
+	if err := connectChain(ctx, true); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	if *withIndexer {
+		if err := rewindCheckpoints(ctx, *rewind); err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eventCollect(ctx)
+		}()
+	}
+
+	s := &http.Server{
+		Addr:           fmt.Sprintf(":%s", port()),
+		Handler:        router.E, // set router
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   30 * time.Second,
+		MaxHeaderBytes: 1 << 20, // 1 MB
+		IdleTimeout:    120 * time.Second,
+	}
+	http.DefaultClient.Timeout = 10 * time.Second // set default http timeout
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	// the in-flight requests and the current log of the indexer are finished before the connections are closed
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := s.Shutdown(shutdownCtx)
+	wg.Wait()
+	return err
+}
+
+func index(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	rewind := fs.String("rewind", "", "Rewind the collector checkpoints before indexing, e.g. ProposalCreated=1200,VoteCast=1200")
+	_ = fs.Parse(args)
+
+	mongodb.New()
+	defer mongodb.Disconnect()
+	if err := connectChain(ctx, true); err != nil {
+		return err
+	}
+	if err := rewindCheckpoints(ctx, *rewind); err != nil {
+		return err
+	}
+
+	// no REST API, the metrics and the liveness probe are served on their own port
+	s := &http.Server{
+		Addr:        fmt.Sprintf(":%s", router.MetricsPort()),
+		Handler:     router.Probes(),
+		ReadTimeout: 10 * time.Second,
+		IdleTimeout: 120 * time.Second,
+	}
+	go func() {
+		if err := s.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Failed metrics listener :: %v\n", err)
+		}
+	}()
+	eventCollect(ctx)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return s.Shutdown(shutdownCtx)
+}
+
+func migrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	mongodb.New()
+	defer mongodb.Disconnect()
+	if err := mongodb.Migrate(ctx); err != nil {
+		return err
+	}
+	log.Println("Migrate finished")
+	return nil
+}
+
+func reindex(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ExitOnError)
+	from := fs.Uint64("from", 0, "First block of the range")
+	to := fs.Uint64("to", 0, "Last block of the range, the last confirmed block when 0")
+	events := fs.String("events", "", "Events to reindex, e.g. ProposalCreated,VoteCast, all collected events when empty")
+	_ = fs.Parse(args)
+
+	mongodb.New()
+	defer mongodb.Disconnect()
+	if err := connectChain(ctx, false); err != nil {
+		return err
+	}
+
+	// the lease keeps the indexer from saving the same logs at the same time
+	leader := event_logger.NewLeader(event_logger.LeaseIndexer)
+	leader.Start(ctx)
+	defer leader.Release(ctx)
+	term, ok := leader.Term() // the reindex stops when the lease is lost
+	if !ok {
+		return errors.New("the indexer lease is held by another instance, stop the indexer before reindexing")
+	}
+	if err := event_logger.Reindex(term, *from, *to, eventNames(*events)); err != nil {
+		return err
+	}
+	log.Println("Reindex finished")
+	return nil
+}
+
+func rebuild(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebuild", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	mongodb.New()
+	defer mongodb.Disconnect()
+	if err := connectChain(ctx, false); err != nil { // the contracts are only used to decode the archived logs
+		return err
+	}
+
+	// the lease keeps the indexer from writing to the collections while they are rebuilt and swapped
+	leader := event_logger.NewLeader(event_logger.LeaseIndexer)
+	leader.Start(ctx)
+	defer leader.Release(ctx)
+	term, ok := leader.Term() // the replay stops when the lease is lost
+	if !ok {
+		return errors.New("the indexer lease is held by another instance, stop the indexer before rebuilding")
+	}
+	return chain.Rebuild(term, leader.IsLeader)
+}
+
+func verify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	mongodb.New()
+	defer mongodb.Disconnect()
+	if err := connectChain(ctx, false); err != nil {
+		return err
+	}
+
+	failed := 0
+	check := func(name string, err error) {
+		if err != nil {
+			failed++
+			log.Printf("FAIL :: %s :: %v\n", name, err)
+			return
+		}
+		log.Printf("OK   :: %s\n", name)
+	}
+
+	check("mongo ping", mongodb.Conn.Ping(ctx, nil))
+	chainID, err := chain.Pool.ChainID(ctx)
+	check(fmt.Sprintf("rpc chain id %v", chainID), err)
+	head, err := chain.GovCont.BlockNumber(ctx)
+	check(fmt.Sprintf("rpc block number %d", head), err)
+
+	for _, cont := range []*chain.Contract{chain.GovCont, chain.DaoCont} {
+		code, err := chain.Pool.CodeAt(ctx, common.HexToAddress(cont.Address()), nil)
+		if err == nil && len(code) == 0 {
+			err = errors.New("no contract code at the address")
+		}
+		check(fmt.Sprintf("%s contract %s", cont.Name(), cont.Address()), err)
+	}
+
+	// a checkpoint past the chain head means the config points to another chain
+	for _, group := range []struct {
+		contract *chain.Contract
+		events   []string
+	}{
+		{chain.GovCont, event_logger.GovernorEvents},
+		{chain.DaoCont, event_logger.TokenEvents},
+	} {
+		for _, evtName := range group.events {
+			checkpoint, found, err := mongodb.GetCheckpoint(ctx, group.contract.Address(), evtName)
+			if err == nil && found && head > 0 && checkpoint.BlockNumber > head {
+				err = errors.New(fmt.Sprintf("checkpoint %d is past the chain head %d", checkpoint.BlockNumber, head))
+			}
+			check(fmt.Sprintf("checkpoint %s", evtName), err)
+		}
+	}
+
+	// the tallies of the indexed proposals against proposalVotes of the governor
+	cursor, err := mongodb.DB.Collection(chain.NameProposals).Find(ctx, bson.D{
+		{Key: "block_number", Value: bson.D{{Key: "$gt", Value: 0}}},
+	})
+	if err != nil {
+		return err
+	}
+	var proposals []model.Proposal
+	if err = cursor.All(ctx, &proposals); err != nil {
+		return err
+	}
+	for _, proposal := range proposals {
+		check(fmt.Sprintf("vote tally of proposal %s", proposal.ProposalID), verifyTally(ctx, proposal))
+	}
+
+	if failed > 0 {
+		return errors.New(fmt.Sprintf("%d checks failed", failed))
+	}
+	return nil
+}
+
+// verifyTally Compare the stored for/against/abstain votes of the proposal with the governor.
+func verifyTally(ctx context.Context, proposal model.Proposal) error {
+	id, ok := big.NewInt(0).SetString(proposal.ProposalID, 10)
+	if !ok {
+		return errors.New("invalid proposal id")
+	}
+	againstVotes, forVotes, abstainVotes, err := chain.GetProposalVotes(ctx, id)
+	if err != nil {
+		return err
+	}
+	if againstVotes.String() != proposal.AgainstVotes || forVotes.String() != proposal.ForVotes || abstainVotes.String() != proposal.AbstainVotes {
+		return errors.New(fmt.Sprintf("DB [%s/%s/%s] / BlockChain [%s/%s/%s] (for/against/abstain)",
+			proposal.ForVotes, proposal.AgainstVotes, proposal.AbstainVotes, forVotes, againstVotes, abstainVotes))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/viper"
 	"path/filepath"
 	"runtime"
+	"testing"
 )
 
 var C *viper.Viper
@@ -16,7 +17,8 @@ func init() {
 	C.SetConfigName("app")      // name of config file (without extension)
 	C.AddConfigPath(basePath()) // optionally look for config in the working directory
 	err := C.ReadInConfig()     // Find and read the config file
-	if err != nil {             // Handle errors reading the config file
+	// Handle errors reading the config file, the unit tests run without app.yaml
+	if err != nil && !testing.Testing() {
 		panic(fmt.Errorf("Fatal error config file: %w \n", err))
 	}
 }
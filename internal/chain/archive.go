@@ -0,0 +1,58 @@
+package chain
+
+import (
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	"context"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/iancoleman/strcase"
+	"go.mongodb.org/mongo-driver/bson"
+	"reflect"
+	"time"
+)
+
+// archiveLog Store the raw log in the event_logs archive with its arguments decoded from the abi.
+func archiveLog(ctx context.Context, e *Event, log types.Log, header *types.Header) error {
+	args, err := e.args(log)
+	if err != nil {
+		return err
+	}
+	topics := make([]string, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = topic.Hex()
+	}
+
+	return mongodb.SaveArchivedLog(ctx, model.ArchivedLog{
+		Contract:       e.Cont.Name(),
+		Event:          e.Name,
+		Address:        log.Address.Hex(),
+		Topics:         topics,
+		Data:           hexutil.Encode(log.Data),
+		Args:           args,
+		TxHash:         log.TxHash.Hex(),
+		TxIndex:        log.TxIndex,
+		LogIndex:       log.Index,
+		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash.Hex(),
+		BlockCreatedAt: time.Unix(int64(header.Time), 0),
+		Removed:        log.Removed,
+	})
+}
+
+// args Arguments of the log by their snake cased abi names. Events with a custom decoder are unpacked again into a map.
+func (e *Event) args(log types.Log) (bson.M, error) {
+	out, ok := e.Out.(map[string]interface{})
+	if !ok {
+		out = map[string]interface{}{}
+		if err := e.Cont.UnpackLogIntoMap(out, e.Name, log); err != nil {
+			return nil, err
+		}
+	}
+
+	args := bson.M{}
+	for name, value := range out {
+		args[strcase.ToSnake(name)] = toBSONValue(reflect.ValueOf(value))
+	}
+	return args, nil
+}
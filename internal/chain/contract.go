@@ -13,8 +13,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"math/big"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -23,61 +26,101 @@ const (
 	ContractNameGovernor = "governor"
 	retryCnt             = 3
 	FuncPastTotalSupply  = "getPastTotalSupply"
+	FuncState            = "state"
+	FuncQuorum           = "quorum"
+	FuncProposalVotes    = "proposalVotes"
 )
 
+// governorStates ProposalState enum of the OpenZeppelin Governor, in declaration order.
+var governorStates = []string{
+	ProposalStatePending,
+	ProposalStateActive,
+	ProposalStateCanceled,
+	ProposalStateDefeated,
+	ProposalStateSucceeded,
+	ProposalStateQueued,
+	ProposalStateExpired,
+	ProposalStateExecuted,
+}
+
 type Contract struct {
-	name    string
-	address string
-	ecl     *ethclient.Client
-	bound   *bind.BoundContract
-	events  map[string]abi.Event
+	name      string
+	address   string
+	fromBlock uint64
+	pool      *ClientPool
+	bound     *bind.BoundContract
+	events    map[string]abi.Event
+	methods   map[string]abi.Method
 }
 
 var (
-	GovCont         *Contract
-	DaoCont         *Contract
-	daoAddress      = ""
-	governorAddress = ""
+	GovCont           *Contract
+	DaoCont           *Contract
+	daoAddress        = ""
+	governorAddress   = ""
+	daoFromBlock      uint64
+	governorFromBlock uint64
 )
 
-func init() {
+// New Load the governor and token contracts and the RPC pool from the config. Endpoints are dialed on first use.
+func New() error {
 	var err error
-	// validate contract start block
-	fromBlock := config.C.GetString("fromBlock")
-	if _, err = strconv.ParseInt(fromBlock, 10, 64); err != nil {
-		panic(fmt.Sprintf(boraLabsErr.WrongFromBlockNumber, fromBlock))
+	// validate contract start blocks, the token may be deployed before the governor
+	if governorFromBlock, err = parseFromBlock("fromBlock"); err != nil {
+		return err
+	}
+	daoFromBlock = governorFromBlock
+	if config.C.IsSet("tokenFromBlock") {
+		if daoFromBlock, err = parseFromBlock("tokenFromBlock"); err != nil {
+			return err
+		}
 	}
 
 	daoAddress = config.C.GetString("daoAddress")
 	governorAddress = config.C.GetString("governorAddress")
 	checkValues := []string{daoAddress, governorAddress}
 	for _, value := range checkValues {
-		emptyCheck(value)
+		if value == "" {
+			return errors.New(boraLabsErr.EmptyConfigValue)
+		}
 	}
 
-	GovCont, err = NewContract(ContractNameGovernor)
-	if err != nil {
-		panic(err)
+	if Pool, err = NewClientPool(rpcEndpoints()); err != nil {
+		return err
 	}
-	DaoCont, err = NewContract(ContractNameDao)
-	if err != nil {
-		panic(err)
+	if GovCont, err = NewContract(ContractNameGovernor); err != nil {
+		return err
+	}
+	if DaoCont, err = NewContract(ContractNameDao); err != nil {
+		return err
+	}
+	// a wrong name in archiveEvents would fail every collector run
+	for _, cont := range []*Contract{GovCont, DaoCont} {
+		if err = cont.validateArchiveEvents(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func emptyCheck(bindAddress string) {
-	if bindAddress == "" {
-		panic(boraLabsErr.EmptyConfigValue)
+// parseFromBlock Start block configured under the key.
+func parseFromBlock(key string) (uint64, error) {
+	fromBlock := config.C.GetString(key)
+	block, err := strconv.ParseUint(fromBlock, 10, 64)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf(boraLabsErr.WrongStartBlock, key, fromBlock))
 	}
+	return block, nil
 }
 
 func NewContract(name string) (*Contract, error) {
 	var filePath, address string
+	var fromBlock uint64
 	switch name {
 	case ContractNameDao:
-		filePath, address = "abi/BoraLabsDaoToken.json", daoAddress
+		filePath, address, fromBlock = "abi/BoraLabsDaoToken.json", daoAddress, daoFromBlock
 	case ContractNameGovernor:
-		filePath, address = "abi/BoraLabsGovernor.json", governorAddress
+		filePath, address, fromBlock = "abi/BoraLabsGovernor.json", governorAddress, governorFromBlock
 	default:
 		return nil, errors.New(boraLabsErr.FailedNewContract)
 	}
@@ -87,45 +130,200 @@ func NewContract(name string) (*Contract, error) {
 		return nil, err
 	}
 
-	ecl, err := ethclient.DialContext(context.Background(), config.C.GetString("rpcEndpoint"))
-	if err != nil {
-		panic(errors.New(fmt.Sprintf("Dialing error %v", err)))
-	}
-
 	return &Contract{
-		name:    name,
-		address: address,
-		ecl:     ecl,
-		bound:   bind.NewBoundContract(common.HexToAddress(address), a, ecl, ecl, ecl),
-		events:  a.Events,
+		name:      name,
+		address:   address,
+		fromBlock: fromBlock,
+		pool:      Pool,
+		bound:     bind.NewBoundContract(common.HexToAddress(address), a, Pool, nil, nil), // read only
+		events:    a.Events,
+		methods:   a.Methods,
 	}, nil
 }
 
-func GetPastTotalSupply(voteStart time.Time) (result []interface{}, error error) {
-	error = DaoCont.bound.Call(nil, &result, FuncPastTotalSupply, big.NewInt(voteStart.Unix()))
+// Name Contract name, dao or governor.
+func (c *Contract) Name() string {
+	return c.name
+}
+
+// Address Checksum address of the contract.
+func (c *Contract) Address() string {
+	return common.HexToAddress(c.address).String()
+}
+
+// FromBlock Block the collector starts at when an event of the contract has no checkpoint yet.
+func (c *Contract) FromBlock() uint64 {
+	return c.fromBlock
+}
+
+// ContractByAddress Contract deployed at the address.
+func ContractByAddress(address common.Address) (*Contract, error) {
+	for _, c := range []*Contract{GovCont, DaoCont} {
+		if common.HexToAddress(c.address) == address {
+			return c, nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf(boraLabsErr.FailedNewContract, address.Hex()))
+}
+
+// ContractByEvent Contract whose ABI declares the event, governor first.
+func ContractByEvent(evtName string) (*Contract, error) {
+	for _, c := range []*Contract{GovCont, DaoCont} {
+		if _, has := c.events[evtName]; has {
+			return c, nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf(boraLabsErr.InvalidEventName, evtName))
+}
+
+func GetPastTotalSupply(ctx context.Context, voteStart time.Time) (result []interface{}, error error) {
+	error = DaoCont.bound.Call(&bind.CallOpts{Context: ctx}, &result, FuncPastTotalSupply, big.NewInt(voteStart.Unix()))
+	return
+}
+
+// GetQuorum Read the quorum required at the proposal snapshot (voteStart).
+func GetQuorum(ctx context.Context, snapshot time.Time) (result []interface{}, error error) {
+	error = GovCont.bound.Call(&bind.CallOpts{Context: ctx}, &result, FuncQuorum, big.NewInt(snapshot.Unix()))
+	return
+}
+
+// GetProposalVotes Read the against/for/abstain votes counted by the governor.
+func GetProposalVotes(ctx context.Context, proposalId *big.Int) (againstVotes, forVotes, abstainVotes *big.Int, err error) {
+	var result []interface{}
+	if err = GovCont.bound.Call(&bind.CallOpts{Context: ctx}, &result, FuncProposalVotes, proposalId); err != nil {
+		return
+	}
+	if len(result) != 3 {
+		err = errors.New(fmt.Sprintf(boraLabsErr.FailedContractCall, FuncProposalVotes))
+		return
+	}
+	againstVotes, _ = result[0].(*big.Int)
+	forVotes, _ = result[1].(*big.Int)
+	abstainVotes, _ = result[2].(*big.Int)
+	if againstVotes == nil || forVotes == nil || abstainVotes == nil {
+		err = errors.New(fmt.Sprintf(boraLabsErr.FailedContractCall, FuncProposalVotes))
+	}
 	return
 }
 
-func (c *Contract) FilterLogs(eventSignature string, fromBlock uint64) ([]types.Log, error) {
-	return c.ecl.FilterLogs(context.Background(), filterQuery(eventSignature, c.address, fromBlock))
+// CallView Call a view function of the contract and return its first output.
+// Overloaded functions are resolved by the number of params.
+func (c *Contract) CallView(ctx context.Context, rawName string, params ...interface{}) (interface{}, error) {
+	name := rawName
+	for n, method := range c.methods {
+		if method.RawName == rawName && len(method.Inputs) == len(params) {
+			name = n
+			break
+		}
+	}
+
+	var result []interface{}
+	if err := c.bound.Call(&bind.CallOpts{Context: ctx}, &result, name, params...); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, errors.New(fmt.Sprintf(boraLabsErr.FailedContractCall, rawName))
+	}
+	return result[0], nil
+}
+
+// ProposalState Read the state of the proposal from the governor state(uint256) view.
+func (c *Contract) ProposalState(ctx context.Context, proposalId *big.Int) (string, error) {
+	var result []interface{}
+	if err := c.bound.Call(&bind.CallOpts{Context: ctx}, &result, FuncState, proposalId); err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", errors.New(fmt.Sprintf(boraLabsErr.FailedContractCall, FuncState))
+	}
+
+	idx, ok := result[0].(uint8)
+	if !ok || int(idx) >= len(governorStates) {
+		return "", errors.New(fmt.Sprintf(boraLabsErr.UnknownProposalState, result[0]))
+	}
+	return governorStates[idx], nil
+}
+
+// FilterLogs Query the logs of all the events of fromBlock..toBlock in one call, topic0 matching any of the events.
+// A zero toBlock queries up to the chain head.
+func (c *Contract) FilterLogs(ctx context.Context, fromBlock, toBlock uint64, evtNames ...string) ([]types.Log, error) {
+	topics, err := c.EventTopics(evtNames)
+	if err != nil {
+		return nil, err
+	}
+	return c.pool.FilterLogs(ctx, filterQuery(topics, c.address, fromBlock, toBlock))
+}
+
+// SubscribeFilterLogs Subscribe to the new logs of the events over a websocket client.
+func (c *Contract) SubscribeFilterLogs(ctx context.Context, wsEcl *ethclient.Client, evtNames []string, logsCh chan types.Log) (ethereum.Subscription, error) {
+	topics, err := c.EventTopics(evtNames)
+	if err != nil {
+		return nil, err
+	}
+	q := filterQuery(topics, c.address, 0, 0)
+	q.FromBlock = nil // new logs only
+	return wsEcl.SubscribeFilterLogs(ctx, q, logsCh)
+}
+
+// DialStream Dial the websocket endpoint used to subscribe to logs.
+func DialStream(ctx context.Context) (*ethclient.Client, error) {
+	wsEndpoint := config.C.GetString("wsEndpoint")
+	if wsEndpoint == "" {
+		return nil, errors.New(boraLabsErr.EmptyConfigValue)
+	}
+	return ethclient.DialContext(ctx, wsEndpoint)
 }
 
-func (c *Contract) SubscribeFilterLogs(eventSignature string, fromBlock uint64, logsCh chan types.Log) (ethereum.Subscription, error) {
-	return c.ecl.SubscribeFilterLogs(context.Background(), filterQuery(eventSignature, c.address, fromBlock), logsCh)
+// EventTopics Topic0 of the events.
+func (c *Contract) EventTopics(evtNames []string) ([]common.Hash, error) {
+	topics := make([]common.Hash, 0, len(evtNames))
+	for _, evtName := range evtNames {
+		event, has := c.events[evtName]
+		if !has {
+			return nil, errors.New(fmt.Sprintf(boraLabsErr.InvalidEventName, evtName))
+		}
+		topics = append(topics, event.ID)
+	}
+	return topics, nil
+}
+
+// EventName Name of the event whose topic0 is the given signature hash.
+func (c *Contract) EventName(topic common.Hash) (string, error) {
+	for name, event := range c.events {
+		if event.ID == topic {
+			return name, nil
+		}
+	}
+	return "", errors.New(fmt.Sprintf(boraLabsErr.InvalidEventName, topic.Hex()))
+}
+
+func (c *Contract) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.pool.BlockNumber(ctx)
+}
+
+// SafeBlockNumber Latest block number minus the configured confirmations, the newest block the collector indexes.
+func (c *Contract) SafeBlockNumber(ctx context.Context) (uint64, error) {
+	head, err := c.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	confirmations := config.C.GetUint64("confirmations")
+	if head < confirmations {
+		return 0, nil
+	}
+	return head - confirmations, nil
 }
 
-func (c *Contract) BlockByNumber(blockNumber *big.Int) (*types.Block, error) {
-	var bl *types.Block
+func (c *Contract) HeaderByNumber(ctx context.Context, blockNumber *big.Int) (*types.Header, error) {
+	var header *types.Header
 	var err error
 	for i := 0; i < retryCnt; i++ {
-		bl, err = c.ecl.BlockByNumber(context.Background(), blockNumber)
-		if err != nil {
-			continue
-		} else {
-			return bl, err
+		header, err = c.pool.HeaderByNumber(ctx, blockNumber)
+		if err == nil {
+			return header, err
 		}
 	}
-	return bl, err
+	return header, err
 }
 
 func (c *Contract) UnpackLogData(out any, evtName string, data types.Log) error {
@@ -136,13 +334,96 @@ func (c *Contract) UnpackLogData(out any, evtName string, data types.Log) error
 	return nil
 }
 
-func filterQuery(eventSignature string, address string, block uint64) ethereum.FilterQuery {
+// UnpackLogIntoMap Unpack the indexed and non-indexed arguments of the log by their abi names.
+func (c *Contract) UnpackLogIntoMap(out map[string]interface{}, evtName string, data types.Log) error {
+	return c.bound.UnpackLogIntoMap(out, evtName, data)
+}
+
+// IsRangeTooLargeErr Whether the node rejected eth_getLogs because of the block range or the number of results.
+// A throttled request is not, shrinking the range does not help against a rate limit.
+func IsRangeTooLargeErr(err error) bool {
+	if err == nil || IsRateLimitErr(err) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range []string{
+		"query returned more than",
+		"too many results",
+		"block range too large",
+		"block range is too large",
+		"block range is too wide",
+		"range too large",
+		"exceed maximum block range",
+		"exceed max block range",
+		"log response size exceeded",
+		"response size exceeded",
+		"response is too big",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// PermanentError Failure of a log that handling it again does not fix, e.g. undecodable data or a proposal that was never created.
+type PermanentError struct {
+	err error
+}
+
+// Permanent Mark the failure of a log as permanent.
+func Permanent(err error) error {
+	return PermanentError{err: err}
+}
+
+func (e PermanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e PermanentError) Unwrap() error {
+	return e.err
+}
+
+// IsPermanentErr Whether retrying the log is pointless until the data it depends on is fixed.
+func IsPermanentErr(err error) bool {
+	var permanent PermanentError
+	return errors.As(err, &permanent)
+}
+
+// IsRateLimitErr Whether the node throttled the request, it succeeds later or on another endpoint.
+func IsRateLimitErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range []string{
+		"rate limit",
+		"too many requests",
+		"request rate exceeded",
+		"request count exceeded",
+		"capacity exceeded",
+		"compute units",
+	} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterQuery(topics []common.Hash, address string, block, toBlock uint64) ethereum.FilterQuery {
+	var to *big.Int
+	if toBlock > 0 {
+		to = big.NewInt(int64(toBlock))
+	}
 	return ethereum.FilterQuery{
 		FromBlock: big.NewInt(int64(block)),
-		ToBlock:   nil,
+		ToBlock:   to,
 		Addresses: []common.Address{common.HexToAddress(address)},
-		Topics: [][]common.Hash{
-			{util.GenerateEventHash(eventSignature)},
-		},
+		Topics:    [][]common.Hash{topics},
 	}
 }
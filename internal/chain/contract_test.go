@@ -0,0 +1,81 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/rpc"
+	"net/http"
+	"testing"
+)
+
+func TestIsPermanentErr(t *testing.T) {
+	cause := errors.New("not found proposal 1")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"transient", cause, false},
+		{"permanent", Permanent(cause), true},
+		{"wrapped permanent", fmt.Errorf("save log :: %w", Permanent(cause)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermanentErr(tt.err); got != tt.want {
+				t.Errorf("IsPermanentErr(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+	if !errors.Is(Permanent(cause), cause) {
+		t.Errorf("Permanent does not unwrap to its cause")
+	}
+}
+
+func TestIsRateLimitErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"http 429", rpc.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}, true},
+		{"wrapped http 429", fmt.Errorf("eth_getLogs :: %w", rpc.HTTPError{StatusCode: http.StatusTooManyRequests}), true},
+		{"http 503", rpc.HTTPError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}, false},
+		{"rate limit message", errors.New("Rate limit exceeded, retry later"), true},
+		{"compute units message", errors.New("Your app has exceeded its compute units per second capacity"), true},
+		{"range too large", errors.New("query returned more than 10000 results"), false},
+		{"other", errors.New("execution reverted"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRateLimitErr(tt.err); got != tt.want {
+				t.Errorf("IsRateLimitErr(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRangeTooLargeErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"too many results", errors.New("query returned more than 10000 results"), true},
+		{"block range", errors.New("eth_getLogs is limited to a 10,000 block range: block range too large"), true},
+		{"max block range", errors.New("exceed maximum block range: 5000"), true},
+		{"response size", errors.New("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"), true},
+		{"rate limited is not a range error", errors.New("too many requests, rate limit exceeded"), false},
+		{"http 429", rpc.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}, false},
+		{"other", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRangeTooLargeErr(tt.err); got != tt.want {
+				t.Errorf("IsRangeTooLargeErr(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
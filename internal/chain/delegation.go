@@ -0,0 +1,85 @@
+package chain
+
+import (
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const (
+	NameDelegations          = mongodb.CollectionDelegations
+	NameDelegateVotesHistory = mongodb.CollectionDelegateVotesHistory
+)
+
+// saveDelegation Keep the current delegate of the delegator. Older logs never overwrite a newer delegation:
+// the upsert only matches an older delegation, and inserting next to a newer one fails on the unique delegator index.
+func saveDelegation(ctx context.Context, t Target, data *model.DelegateChangedLog, log types.Log, header *types.Header) error {
+	d := model.Delegation{
+		Delegator:        data.Delegator.String(),
+		Delegate:         data.ToDelegate.String(),
+		PreviousDelegate: data.FromDelegate.String(),
+		BlockNumber:      log.BlockNumber,
+		LogIndex:         log.Index,
+		BlockHash:        log.BlockHash.Hex(),
+		TxHash:           log.TxHash.Hex(),
+		BlockCreatedAt:   time.Unix(int64(header.Time), 0),
+		UpdatedAt:        time.Now(),
+	}
+
+	opt := options.Update().SetUpsert(true)
+	_, err := t.Collection(NameDelegations).UpdateOne(ctx, bson.D{
+		{Key: "delegator", Value: d.Delegator},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "block_number", Value: bson.D{{Key: "$lt", Value: d.BlockNumber}}}},
+			bson.D{{Key: "block_number", Value: d.BlockNumber}, {Key: "log_index", Value: bson.D{{Key: "$lte", Value: d.LogIndex}}}},
+		}},
+	}, bson.D{{Key: "$set", Value: d}}, opt)
+	if mongo2.IsDuplicateKeyError(err) {
+		return nil // a newer delegation is stored
+	}
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
+		return err
+	}
+	util.Log(fmt.Sprintf("Successfully %s [%s -> %s]", EventNameDelegateChanged, d.Delegator, d.Delegate))
+	return nil
+}
+
+// saveDelegateVotes Append the voting power change of the delegate to its history.
+func saveDelegateVotes(ctx context.Context, t Target, data *model.DelegateVotesChangedLog, log types.Log, header *types.Header) error {
+	if data.PreviousBalance == nil || data.NewBalance == nil {
+		return errors.New(boraLabsErr.FailedParseLogData)
+	}
+
+	v := model.DelegateVotes{
+		Delegate:        data.Delegate.String(),
+		PreviousBalance: data.PreviousBalance.String(),
+		NewBalance:      data.NewBalance.String(),
+		BlockNumber:     log.BlockNumber,
+		LogIndex:        log.Index,
+		BlockHash:       log.BlockHash.Hex(),
+		TxHash:          log.TxHash.Hex(),
+		BlockCreatedAt:  time.Unix(int64(header.Time), 0),
+	}
+
+	opt := options.Update().SetUpsert(true)
+	_, err := t.Collection(NameDelegateVotesHistory).UpdateOne(ctx, bson.D{
+		{Key: "tx_hash", Value: v.TxHash},
+		{Key: "log_index", Value: v.LogIndex},
+	}, bson.D{{Key: "$set", Value: v}}, opt)
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
+		return err
+	}
+	util.Log(fmt.Sprintf("Successfully %s [%s] [%s]", EventNameDelegateVotesChanged, v.Delegate, v.NewBalance))
+	return nil
+}
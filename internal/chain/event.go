@@ -10,180 +10,328 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/iancoleman/strcase"
 	"go.mongodb.org/mongo-driver/bson"
 	mongo2 "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 	"math/big"
+	"sync"
 	"time"
 )
 
+// saveMu Serializes the writes of the streamer, the collectors and the retries, e.g. a VoteCast log
+// saved by the streamer and the collector at once would otherwise insert the vote twice.
+var saveMu sync.Mutex
+
 type Event struct {
 	Name      string
 	Signature string
 	Out       any
 	Cont      *Contract
+	Target    Target // collections the handlers write to, the live ones when zero
 }
 
 const (
-	NameProposals            = "proposals"
-	NameVotes                = "votes"
-	EventNameProposalCreated = "ProposalCreated"
-	EventNameVoteCast        = "VoteCast"
-	ProposalStatePending     = "pending"
-	ProposalStateActive      = "active"
-	ProposalStateClosed      = "closed"
+	NameProposals                   = "proposals"
+	NameVotes                       = mongodb.CollectionVotes
+	EventNameProposalCreated        = "ProposalCreated"
+	EventNameVoteCast               = "VoteCast"
+	EventNameProposalCanceled       = "ProposalCanceled"
+	EventNameProposalExecuted       = "ProposalExecuted"
+	EventNameDelegateChanged        = "DelegateChanged"
+	EventNameDelegateVotesChanged   = "DelegateVotesChanged"
+	EventNameVotingDelaySet         = "VotingDelaySet"
+	EventNameVotingPeriodSet        = "VotingPeriodSet"
+	EventNameProposalThresholdSet   = "ProposalThresholdSet"
+	EventNameQuorumNumeratorUpdated = "QuorumNumeratorUpdated"
+	ProposalStatePending            = "pending"
+	ProposalStateActive             = "active"
+	ProposalStateClosed             = "closed"
+	ProposalStateCanceled           = "canceled"
+	ProposalStateExecuted           = "executed"
+	ProposalStateDefeated           = "defeated"
+	ProposalStateSucceeded          = "succeeded"
+	ProposalStateQueued             = "queued"
+	ProposalStateExpired            = "expired"
 )
 
-func (e *Event) SaveLog(proposalID string, log types.Log) error {
-	evt := *e
-	var ok bool
-	coll := mongodb.DB.Collection(fmt.Sprintf("%s_logs", strcase.ToSnake(e.Name)))
-	block, err := e.Cont.BlockByNumber(big.NewInt(int64(log.BlockNumber)))
+func (e *Event) SaveLog(ctx context.Context, proposalID string, log types.Log) error {
+	if log.Removed { // the block of the log was reorganized out of the chain
+		return Rollback(ctx, log.BlockNumber)
+	}
+
+	header, err := e.Cont.BlockHeader(ctx, log.BlockNumber, log.BlockHash)
 	if err != nil {
 		return errors.New(fmt.Sprintf("%s\n%v", boraLabsErr.FailedBlockByNumber, err))
 	}
+	if header.Hash() != log.BlockHash { // skipped, the canonical log of the block is collected instead
+		util.Log(fmt.Sprintf(boraLabsErr.OrphanedLog, log.BlockNumber, log.BlockHash.Hex()))
+		return nil
+	}
+	ctx = context.WithoutCancel(ctx) // from the first write on the log is saved completely, even on shutdown
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	if err = RecordBlock(ctx, log.BlockNumber, log.BlockHash.Hex()); err != nil {
+		return errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
+	}
+	if err = archiveLog(ctx, e, log, header); err != nil {
+		return errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
+	}
 
-	switch evt.Name {
-	case EventNameProposalCreated:
-		var data *model.ProposalCreatedLog
-		if data, ok = evt.Out.(*model.ProposalCreatedLog); !ok {
-			util.ErrorLog(errors.New(boraLabsErr.FailedParseLogData))
-		}
-		if proposalID != "" && data.ProposalId.String() != proposalID {
+	return e.handler()(ctx, e, proposalID, log, header)
+}
+
+// saveProposalCreated Store a ProposalCreated log and the chain data of its proposal.
+func saveProposalCreated(ctx context.Context, e *Event, proposalID string, log types.Log, header *types.Header) error {
+	var ok bool
+	var err error
+	coll := e.Target.logCollection(e.Name)
+	var data *model.ProposalCreatedLog
+	if data, ok = e.Out.(*model.ProposalCreatedLog); !ok {
+		return errors.New(boraLabsErr.FailedParseLogData)
+	}
+	if proposalID != "" && data.ProposalId.String() != proposalID {
+		return nil
+	}
+
+	m := model.MongoProposalCreatedLog{
+		ProposalId:     data.ProposalId.String(),
+		Proposer:       data.Proposer.String(),
+		Targets:        util.ConvArrayToStringArr(data.Targets),
+		Values:         util.ConvArrayToStringArr(data.Values),
+		Signatures:     data.Signatures,
+		Calldatas:      util.ConvArrayToStringArr(data.Calldatas),
+		VoteStart:      time.Unix(data.VoteStart.Int64(), 0),
+		VoteEnd:        time.Unix(data.VoteEnd.Int64(), 0),
+		Description:    data.Description,
+		Log:            log,
+		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash.Hex(),
+		BlockCreatedAt: time.Unix(int64(header.Time), 0),
+		UpdatedAt:      time.Now(),
+	}
+
+	// log update
+	opt := options.Update().SetUpsert(true)
+	_, err = coll.UpdateOne(ctx, bson.D{
+		{Key: "proposal_id", Value: m.ProposalId},
+	}, bson.D{{"$set", m}}, opt)
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
+		return err
+	}
+
+	// get proposal for totalSupply
+	var proposal model.Proposal
+	err = e.Target.Collection(NameProposals).FindOne(ctx, bson.D{
+		{"proposal_id", data.ProposalId.String()},
+	}).Decode(&proposal)
+	if err != nil {
+		if errors.Is(err, mongo2.ErrNoDocuments) {
 			return nil
 		}
+		return errors.New(fmt.Sprintf("Error find proposal %s\n%v", data.ProposalId.String(), err))
+	}
 
-		m := model.MongoProposalCreatedLog{
-			ProposalId:     data.ProposalId.String(),
-			Proposer:       data.Proposer.String(),
-			Targets:        util.ConvArrayToStringArr(data.Targets),
-			Values:         util.ConvArrayToStringArr(data.Values),
-			Signatures:     data.Signatures,
-			Calldatas:      util.ConvArrayToStringArr(data.Calldatas),
-			VoteStart:      time.Unix(data.VoteStart.Int64(), 0),
-			VoteEnd:        time.Unix(data.VoteEnd.Int64(), 0),
-			Description:    data.Description,
-			Log:            log,
-			BlockCreatedAt: time.Unix(int64(block.Time()), 0),
-			UpdatedAt:      time.Now(),
-		}
+	// get total supply
+	totalSupply, totalVotingPower, votingRatio := CalcTotalSupply(ctx, e.Target, proposal, proposal.StartDate)
+	tally := CalcVoteTally(ctx, e.Target, proposal, proposal.StartDate)
 
-		// log update
-		opt := options.Update().SetUpsert(true)
-		_, err = coll.UpdateOne(context.Background(), bson.D{
-			{Key: "proposal_id", Value: m.ProposalId},
-		}, bson.D{{"$set", m}}, opt)
-		if err != nil {
-			util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
-			return err
+	// proposals update
+	proposalState := proposal.State // offline the seeded state is kept, the dates cannot tell succeeded from defeated
+	if !e.Target.offline {
+		if state, err := ResolveProposalState(ctx, m.ProposalId); err == nil {
+			proposalState = state
+		} else { // the scheduler reads it again at the next date of the proposal
+			util.Log(err.Error())
 		}
+	} else if proposalState == "" {
+		proposalState = CalcProposalState(m.VoteStart, m.VoteEnd)
+	}
+	if IsFinalProposalState(proposal.State) { // finished proposals never go back to a time based state
+		proposalState = proposal.State
+	}
+	_, err = e.Target.Collection(NameProposals).UpdateOne(
+		ctx,
+		bson.D{{Key: "proposal_id", Value: m.ProposalId}},
+		bson.D{
+			{"$set", append(bson.D{
+				{Key: "block_number", Value: header.Number.Uint64()},
+				{Key: "block_hash", Value: log.BlockHash.Hex()},
+				{Key: "tx_hash", Value: log.TxHash.Hex()},
+				{Key: "start_date", Value: m.VoteStart},
+				{Key: "end_date", Value: m.VoteEnd},
+				{Key: "proposer", Value: m.Proposer},
+				{Key: "total_voting_power", Value: totalVotingPower.String()},
+				{Key: "total_supply", Value: totalSupply.String()},
+				{Key: "voting_ratio", Value: votingRatio.String()},
+				{Key: "state", Value: proposalState},
+			}, tally.fields()...)},
+		}, opt)
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
+		return err
+	}
+	util.Log(fmt.Sprintf("Successfully %s [%s] [%s]", e.Name, data.ProposalId.String(), proposalState))
+	return nil
+}
 
-		// get proposal for totalSupply
-		var proposal model.Proposal
-		err = mongodb.DB.Collection(NameProposals).FindOne(context.Background(), bson.D{
-			{"proposal_id", data.ProposalId.String()},
-		}).Decode(&proposal)
-		if err != nil {
-			if errors.Is(err, mongo2.ErrNoDocuments) {
-				return nil
-			}
-			return errors.New(fmt.Sprintf("Error find proposal %s\n%v", data.ProposalId.String(), err))
-		}
+// saveVoteCast Store a VoteCast log and the vote it casts.
+func saveVoteCast(ctx context.Context, e *Event, proposalID string, log types.Log, header *types.Header) error {
+	var ok bool
+	var err error
+	coll := e.Target.logCollection(e.Name)
+	var data *model.VoteCastLog
+	if data, ok = e.Out.(*model.VoteCastLog); !ok {
+		return errors.New(boraLabsErr.FailedParseLogData)
+	}
 
-		// get total supply
-		totalSupply, totalVotingPower, votingRatio := CalcTotalSupply(proposal, proposal.StartDate)
+	if proposalID != "" && data.ProposalId.String() != proposalID {
+		return nil
+	}
 
-		// proposals update
-		proposalState := CalcProposalState(m.VoteStart, m.VoteEnd)
-		_, err = mongodb.DB.Collection(NameProposals).UpdateOne(
-			context.Background(),
-			bson.D{{Key: "proposal_id", Value: m.ProposalId}},
-			bson.D{
-				{"$set", bson.D{
-					{Key: "block_number", Value: block.Header().Number.Uint64()},
-					{Key: "tx_hash", Value: log.TxHash.Hex()},
-					{Key: "start_date", Value: m.VoteStart},
-					{Key: "end_date", Value: m.VoteEnd},
-					{Key: "proposer", Value: m.Proposer},
-					{Key: "total_voting_power", Value: totalVotingPower.String()},
-					{Key: "total_supply", Value: totalSupply.String()},
-					{Key: "voting_ratio", Value: votingRatio.String()},
-					{Key: "state", Value: proposalState},
-				}},
-			}, opt)
-		if err != nil {
-			util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
-			return err
-		}
-		util.Log(fmt.Sprintf("Successfully %s [%s] [%s]", evt.Name, data.ProposalId.String(), proposalState))
+	v := model.MongoVoteCastLog{
+		Voter:       data.Voter.String(),
+		ProposalId:  data.ProposalId.String(),
+		Support:     data.Support,
+		Weight:      uint8(data.Weight.Uint64()),
+		Reason:      data.Reason,
+		TxHash:      log.TxHash.Hex(),
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash.Hex(),
+		CreatedAt:   data.CreatedAt,
+	}
+	// log update
+	opt := options.Update().SetUpsert(true)
+	_, err = coll.UpdateOne(ctx, bson.D{
+		{Key: "proposal_id", Value: v.ProposalId},
+		{Key: "voter", Value: v.Voter},
+	}, bson.D{{"$set", v}}, opt)
+	if err != nil {
+		return errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
+	}
 
-	case EventNameVoteCast:
-		var data *model.VoteCastLog
-		if data, ok = e.Out.(*model.VoteCastLog); !ok {
-			return errors.New(boraLabsErr.FailedParseLogData)
-		}
+	if checkExistsProposal(ctx, e.Target, data.ProposalId.String()) == false {
+		return Permanent(errors.New(fmt.Sprintf(boraLabsErr.NotFoundProposal, data.ProposalId.String())))
+	}
 
-		if proposalID != "" && data.ProposalId.String() != proposalID {
-			return nil
+	// the id is only drawn for a new vote, a rebuild keeps the ids of the seeded votes
+	// and draws the new ones after them from the rebuilt collection
+	filter := bson.D{
+		{Key: "proposal_id", Value: v.ProposalId},
+		{Key: "wallet_address", Value: v.Voter},
+	}
+	vote := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "voting_power", Value: data.Weight.String()},
+		{Key: "status", Value: data.Support},
+		{Key: "tx_hash", Value: log.TxHash.Hex()},
+		{Key: "block_number", Value: log.BlockNumber},
+		{Key: "block_hash", Value: log.BlockHash.Hex()},
+		{Key: "created_at", Value: time.Unix(int64(header.Time), 0)},
+	}}}
+	res, err := e.Target.Collection(NameVotes).UpdateOne(ctx, filter, vote)
+	if err == nil && res.MatchedCount == 0 {
+		vote = append(vote, bson.E{Key: "$setOnInsert", Value: bson.D{{Key: "id", Value: mongodb.NextSequence(NameVotes + e.Target.suffix)}}})
+		_, err = e.Target.Collection(NameVotes).UpdateOne(ctx, filter, vote, opt)
+		if mongo2.IsDuplicateKeyError(err) { // inserted meanwhile by another instance, e.g. the proposal updater of an API replica
+			_, err = e.Target.Collection(NameVotes).UpdateOne(ctx, filter, vote[:1])
 		}
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
+	}
+	util.Log(fmt.Sprintf("Successfully %s [%s]", e.Name, data.ProposalId.String()))
 
-		v := model.MongoVoteCastLog{
-			Voter:      data.Voter.String(),
-			ProposalId: data.ProposalId.String(),
-			Support:    data.Support,
-			Weight:     uint8(data.Weight.Uint64()),
-			Reason:     data.Reason,
-			CreatedAt:  data.CreatedAt,
-		}
-		// log update
-		opt := options.Update().SetUpsert(true)
-		_, err = coll.UpdateOne(context.Background(), bson.D{
-			{Key: "proposal_id", Value: v.ProposalId},
-			{Key: "voter", Value: v.Voter},
-		}, bson.D{{"$set", v}}, opt)
-		if err != nil {
-			return errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
-		}
+	if err = updateTotalSupply(ctx, e.Target, data.ProposalId.String()); err != nil {
+		util.Log(fmt.Sprintf("Failed update total supply :: [Proposal ID:%s]", data.ProposalId.String()))
+	}
+	return nil
+}
 
-		if checkExistsProposal(data.ProposalId.String()) == false {
-			return errors.New(fmt.Sprintf("Not found proposal %s", data.ProposalId.String()))
-		}
+// saveProposalFinalState Store a ProposalCanceled/ProposalExecuted log and move the proposal into the given final state.
+func saveProposalFinalState(ctx context.Context, t Target, evtName, proposalID string, id *big.Int, state string, log types.Log, header *types.Header) error {
+	if id == nil {
+		return errors.New(boraLabsErr.FailedParseLogData)
+	}
+	if proposalID != "" && id.String() != proposalID {
+		return nil
+	}
 
-		_, err = mongodb.DB.Collection(NameVotes).
-			UpdateOne(context.Background(), bson.D{
-				{Key: "proposal_id", Value: v.ProposalId},
-				{Key: "wallet_address", Value: v.Voter},
-			},
-				bson.D{
-					{"$setOnInsert", bson.D{
-						{Key: "id", Value: mongodb.NextSequence(NameVotes)},
-						{Key: "proposal_id", Value: v.ProposalId},
-						{Key: "wallet_address", Value: v.Voter},
-						{Key: "voting_power", Value: data.Weight.String()},
-						{Key: "status", Value: data.Support},
-						{Key: "tx_hash", Value: log.TxHash.Hex()},
-						{Key: "created_at", Value: time.Now()},
-					}},
-				}, opt)
-		if err != nil {
-			util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
-		}
-		util.Log(fmt.Sprintf("Successfully %s [%s]", evt.Name, data.ProposalId.String()))
+	blockCreatedAt := time.Unix(int64(header.Time), 0)
+	m := model.MongoProposalFinalLog{
+		Event:          evtName,
+		ProposalId:     id.String(),
+		TxHash:         log.TxHash.Hex(),
+		Log:            log,
+		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash.Hex(),
+		BlockCreatedAt: blockCreatedAt,
+		UpdatedAt:      time.Now(),
+	}
+	// log update
+	opt := options.Update().SetUpsert(true)
+	_, err := t.logCollection(evtName).UpdateOne(ctx, bson.D{
+		{Key: "proposal_id", Value: m.ProposalId},
+	}, bson.D{{Key: "$set", Value: m}}, opt)
+	if err != nil {
+		return errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
+	}
 
-		if err = updateTotalSupply(data.ProposalId.String()); err != nil {
-			util.Log(fmt.Sprintf("Failed update total supply :: [Proposal ID:%s]", data.ProposalId.String()))
-		}
+	if checkExistsProposal(ctx, t, m.ProposalId) == false {
+		return Permanent(errors.New(fmt.Sprintf(boraLabsErr.NotFoundProposal, m.ProposalId)))
 	}
 
+	txHashKey, dateKey := "canceled_tx_hash", "canceled_at"
+	if state == ProposalStateExecuted {
+		txHashKey, dateKey = "executed_tx_hash", "executed_at"
+	}
+
+	// proposals update
+	_, err = t.Collection(NameProposals).UpdateOne(
+		ctx,
+		bson.D{{Key: "proposal_id", Value: m.ProposalId}},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "state", Value: state},
+				{Key: txHashKey, Value: m.TxHash},
+				{Key: dateKey, Value: blockCreatedAt},
+			}},
+		})
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
+		return err
+	}
+	util.Log(fmt.Sprintf("Successfully %s [%s] [%s]", evtName, m.ProposalId, state))
 	return nil
 }
 
-func checkExistsProposal(proposalId string) bool {
+// IsFinalProposalState Whether the proposal can no longer change state on the governor.
+func IsFinalProposalState(state string) bool {
+	switch state {
+	case ProposalStateCanceled, ProposalStateExecuted, ProposalStateDefeated, ProposalStateExpired:
+		return true
+	}
+	return false
+}
+
+// ResolveProposalState Read the proposal state from the governor.
+// On an error the callers keep the stored state, the dates cannot tell succeeded from defeated.
+func ResolveProposalState(ctx context.Context, proposalId string) (string, error) {
+	id, ok := big.NewInt(0).SetString(proposalId, 10)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("Invalid proposal id %s", proposalId))
+	}
+	state, err := GovCont.ProposalState(ctx, id)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Failed read proposal state [%s] :: %v", proposalId, err))
+	}
+	return state, nil
+}
+
+func checkExistsProposal(ctx context.Context, t Target, proposalId string) bool {
 	// is existing check
-	coll := mongodb.DB.Collection(NameProposals)
-	res := coll.FindOne(context.Background(), bson.D{
+	coll := t.Collection(NameProposals)
+	res := coll.FindOne(ctx, bson.D{
 		{Key: "proposal_id", Value: proposalId},
 	})
 	if res.Err() == nil {
@@ -202,18 +350,23 @@ func (e *Event) New(name string) (evt Event, err error) {
 
 	e.Name = name
 	e.Signature = event.Sig
-	switch name {
-	case EventNameProposalCreated:
-		e.Out = &model.ProposalCreatedLog{}
-	case EventNameVoteCast:
-		e.Out = &model.VoteCastLog{}
-	default:
-		err = errors.New(fmt.Sprintf(boraLabsErr.InvalidEventName, name))
+	if entry, has := registry[name]; has {
+		e.Out = entry.newOut()
+	} else { // decoded generically from the abi
+		e.Out = map[string]interface{}{}
 	}
 	evt = *e
 	return
 }
 
+// Decode Unpack the log into the output of the event.
+func (e *Event) Decode(log types.Log) error {
+	if out, ok := e.Out.(map[string]interface{}); ok {
+		return e.Cont.UnpackLogIntoMap(out, e.Name, log)
+	}
+	return e.Cont.UnpackLogData(e.Out, e.Name, log)
+}
+
 // CalcProposalState Calculate the proposal state based on the current time.
 func CalcProposalState(startDt, endDt time.Time) (state string) {
 	now := time.Now()
@@ -242,34 +395,40 @@ func CalcProposalState(startDt, endDt time.Time) (state string) {
 	return
 }
 
-func updateTotalSupply(proposalId string) error {
+// UpdateTotalSupply Recompute the total supply, the voting ratio and the vote tally of the proposal at its snapshot.
+func UpdateTotalSupply(ctx context.Context, proposalId string) error {
+	return updateTotalSupply(ctx, Target{}, proposalId)
+}
+
+func updateTotalSupply(ctx context.Context, t Target, proposalId string) error {
 	var err error
 	var proposal model.Proposal
-	err = mongodb.DB.Collection(NameProposals).FindOne(context.Background(), bson.D{
+	err = t.Collection(NameProposals).FindOne(ctx, bson.D{
 		{"proposal_id", proposalId},
 	}).Decode(&proposal)
 	if err != nil {
 		if errors.Is(err, mongo2.ErrNoDocuments) {
-			return errors.New(fmt.Sprintf("Not found proposal %s", proposalId))
+			return Permanent(errors.New(fmt.Sprintf(boraLabsErr.NotFoundProposal, proposalId)))
 		}
 		return errors.New(fmt.Sprintf("Error find proposal %s\n%v", proposalId, err))
 	}
 
 	// get total supply
-	totalSupply, totalVotingPower, votingRatio := CalcTotalSupply(proposal, proposal.StartDate)
+	totalSupply, totalVotingPower, votingRatio := CalcTotalSupply(ctx, t, proposal, proposal.StartDate)
+	tally := CalcVoteTally(ctx, t, proposal, proposal.StartDate)
 
 	// proposals update
 	if totalSupply.Cmp(big.NewInt(0)) > 0 || totalVotingPower.Cmp(big.NewInt(0)) > 0 || votingRatio.Cmp(big.NewInt(0)) > 0 {
 		opt := options.Update().SetUpsert(true)
-		_, err = mongodb.DB.Collection(NameProposals).UpdateOne(
-			context.Background(),
+		_, err = t.Collection(NameProposals).UpdateOne(
+			ctx,
 			bson.D{{Key: "proposal_id", Value: proposalId}},
 			bson.D{
-				{"$set", bson.D{
+				{"$set", append(bson.D{
 					{Key: "total_voting_power", Value: totalVotingPower.String()},
 					{Key: "total_supply", Value: totalSupply.String()},
 					{Key: "voting_ratio", Value: votingRatio.String()},
-				}},
+				}, tally.fields()...)},
 			}, opt)
 		if err != nil {
 			util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
@@ -279,7 +438,7 @@ func updateTotalSupply(proposalId string) error {
 	return err
 }
 
-func CalcTotalSupply(proposal model.Proposal, startDt time.Time) (totalSupply, totalVotingPower, votingRatio *big.Int) {
+func CalcTotalSupply(ctx context.Context, t Target, proposal model.Proposal, startDt time.Time) (totalSupply, totalVotingPower, votingRatio *big.Int) {
 	totalSupply = big.NewInt(0)
 	totalVotingPower = big.NewInt(0)
 	votingRatio = big.NewInt(0)
@@ -290,7 +449,10 @@ func CalcTotalSupply(proposal model.Proposal, startDt time.Time) (totalSupply, t
 	}
 
 	// get total supply
-	totalSupplyList, _ := GetPastTotalSupply(startDt)
+	var totalSupplyList []interface{}
+	if !t.offline {
+		totalSupplyList, _ = GetPastTotalSupply(ctx, startDt)
+	}
 
 	if len(totalSupplyList) > 0 && totalSupplyList[0] != nil {
 		totalSupply = totalSupplyList[0].(*big.Int)
@@ -305,8 +467,8 @@ func CalcTotalSupply(proposal model.Proposal, startDt time.Time) (totalSupply, t
 
 	// calculate voting status
 	if totalSupply.String() != "" {
-		voteColl := mongodb.DB.Collection(NameVotes)
-		cursor, err := voteColl.Find(context.Background(), bson.D{
+		voteColl := t.Collection(NameVotes)
+		cursor, err := voteColl.Find(ctx, bson.D{
 			{"proposal_id", proposal.ProposalID},
 			{"voting_power", bson.M{"$ne": "0"}},
 		})
@@ -314,9 +476,9 @@ func CalcTotalSupply(proposal model.Proposal, startDt time.Time) (totalSupply, t
 			panic(err)
 		}
 
-		defer cursor.Close(context.TODO())
+		defer cursor.Close(ctx)
 
-		for cursor.Next(context.TODO()) {
+		for cursor.Next(ctx) {
 			var vote model.VoteCast
 			err = cursor.Decode(&vote)
 			if err != nil {
@@ -336,3 +498,112 @@ func CalcTotalSupply(proposal model.Proposal, startDt time.Time) (totalSupply, t
 	}
 	return
 }
+
+// VoteTally Per-support voting power of a proposal and its quorum.
+type VoteTally struct {
+	ForVotes      *big.Int
+	AgainstVotes  *big.Int
+	AbstainVotes  *big.Int
+	Quorum        *big.Int
+	QuorumReached bool
+}
+
+// count Add the voting power of a vote to the total of its support.
+func (t *VoteTally) count(support uint8, votingPower *big.Int) {
+	switch support {
+	case model.StatusYes:
+		t.ForVotes.Add(t.ForVotes, votingPower)
+	case model.StatusNo:
+		t.AgainstVotes.Add(t.AgainstVotes, votingPower)
+	case model.StatusAbstain:
+		t.AbstainVotes.Add(t.AbstainVotes, votingPower)
+	}
+}
+
+// quorumReached Like the governor _quorumReached, GovernorCountingSimple counts for and abstain votes towards the quorum,
+// so a quorum of 0 is always reached.
+func quorumReached(quorum, forVotes, abstainVotes *big.Int) bool {
+	return quorum.Cmp(big.NewInt(0).Add(forVotes, abstainVotes)) <= 0
+}
+
+func (t VoteTally) fields() bson.D {
+	return bson.D{
+		{Key: "for_votes", Value: t.ForVotes.String()},
+		{Key: "against_votes", Value: t.AgainstVotes.String()},
+		{Key: "abstain_votes", Value: t.AbstainVotes.String()},
+		{Key: "quorum", Value: t.Quorum.String()},
+		{Key: "quorum_reached", Value: t.QuorumReached},
+	}
+}
+
+// CalcVoteTally Sum the votes collection by support and compare it with the quorum at the proposal snapshot.
+// The governor proposalVotes(id) is read as a cross-check and a mismatch is logged.
+func CalcVoteTally(ctx context.Context, t Target, proposal model.Proposal, startDt time.Time) (tally VoteTally) {
+	tally = VoteTally{
+		ForVotes:     big.NewInt(0),
+		AgainstVotes: big.NewInt(0),
+		AbstainVotes: big.NewInt(0),
+		Quorum:       big.NewInt(0),
+	}
+
+	cursor, err := t.Collection(NameVotes).Find(ctx, bson.D{
+		{Key: "proposal_id", Value: proposal.ProposalID},
+	})
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf("[%s] find votes failed :: %v", proposal.ProposalID, err)))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var vote model.VoteCast
+		if err = cursor.Decode(&vote); err != nil {
+			log.Println(err)
+			continue
+		}
+
+		votingPower, ok := big.NewInt(0).SetString(vote.VotingPower, 10)
+		if !ok {
+			log.Println("convert failed voting power :: ", vote.VotingPower)
+			continue
+		}
+		tally.count(vote.Status, votingPower)
+	}
+
+	if proposal.State == ProposalStatePending || proposal.State == "" || startDt.IsZero() { // The quorum can only be read for a past snapshot.
+		return
+	}
+
+	var quorumList []interface{}
+	if !t.offline {
+		if quorumList, err = GetQuorum(ctx, startDt); err != nil {
+			log.Printf("[%s] get quorum failed :: %v\n", proposal.ProposalID, err)
+		}
+	}
+	quorumKnown := true
+	if len(quorumList) > 0 && quorumList[0] != nil {
+		tally.Quorum = quorumList[0].(*big.Int)
+	} else if quorum, ok := big.NewInt(0).SetString(proposal.Quorum, 10); ok {
+		tally.Quorum = quorum
+	} else {
+		quorumKnown = false // neither read nor stored, it is not reported as reached
+	}
+	tally.QuorumReached = quorumKnown && quorumReached(tally.Quorum, tally.ForVotes, tally.AbstainVotes)
+
+	// cross-check with the governor
+	proposalId, ok := big.NewInt(0).SetString(proposal.ProposalID, 10)
+	if !ok || t.offline {
+		return
+	}
+	againstVotes, forVotes, abstainVotes, err := GetProposalVotes(ctx, proposalId)
+	if err != nil {
+		log.Printf("[%s] get proposal votes failed :: %v\n", proposal.ProposalID, err)
+		return
+	}
+	if againstVotes.Cmp(tally.AgainstVotes) != 0 || forVotes.Cmp(tally.ForVotes) != 0 || abstainVotes.Cmp(tally.AbstainVotes) != 0 {
+		// expected while the collector is still catching up with the VoteCast logs
+		log.Printf("[%s] vote tally mismatch :: DB [%s/%s/%s] / BlockChain [%s/%s/%s] (for/against/abstain)\n",
+			proposal.ProposalID, tally.ForVotes, tally.AgainstVotes, tally.AbstainVotes, forVotes, againstVotes, abstainVotes)
+	}
+	return
+}
@@ -0,0 +1,60 @@
+package chain
+
+import (
+	"boralabs/internal/model"
+	"math/big"
+	"testing"
+)
+
+func TestQuorumReached(t *testing.T) {
+	tests := []struct {
+		name                           string
+		quorum, forVotes, abstainVotes int64
+		want                           bool
+	}{
+		{"quorum of 0 without votes", 0, 0, 0, true},
+		{"quorum of 0 with votes", 0, 10, 5, true},
+		{"below the quorum", 100, 60, 39, false},
+		{"abstain votes count", 100, 60, 40, true},
+		{"for votes alone", 100, 100, 0, true},
+		{"abstain votes alone", 100, 0, 150, true},
+		{"no votes", 100, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := quorumReached(big.NewInt(tt.quorum), big.NewInt(tt.forVotes), big.NewInt(tt.abstainVotes))
+			if got != tt.want {
+				t.Errorf("quorumReached(%d, %d, %d) = %v, want %v", tt.quorum, tt.forVotes, tt.abstainVotes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoteTallyCount(t *testing.T) {
+	type vote struct {
+		support     uint8
+		votingPower int64
+	}
+	tests := []struct {
+		name                       string
+		votes                      []vote
+		forVotes, against, abstain int64
+	}{
+		{"no votes", nil, 0, 0, 0},
+		{"one of each", []vote{{model.StatusYes, 10}, {model.StatusNo, 20}, {model.StatusAbstain, 30}}, 10, 20, 30},
+		{"summed by support", []vote{{model.StatusYes, 10}, {model.StatusYes, 15}, {model.StatusNo, 1}}, 25, 1, 0},
+		{"unknown support is ignored", []vote{{model.StatusYes, 10}, {7, 100}}, 10, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := VoteTally{ForVotes: big.NewInt(0), AgainstVotes: big.NewInt(0), AbstainVotes: big.NewInt(0), Quorum: big.NewInt(0)}
+			for _, v := range tt.votes {
+				tally.count(v.support, big.NewInt(v.votingPower))
+			}
+			if tally.ForVotes.Int64() != tt.forVotes || tally.AgainstVotes.Int64() != tt.against || tally.AbstainVotes.Int64() != tt.abstain {
+				t.Errorf("tally = %s/%s/%s, want %d/%d/%d (for/against/abstain)",
+					tally.ForVotes, tally.AgainstVotes, tally.AbstainVotes, tt.forVotes, tt.against, tt.abstain)
+			}
+		})
+	}
+}
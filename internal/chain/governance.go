@@ -0,0 +1,163 @@
+package chain
+
+import (
+	"boralabs/config"
+	"boralabs/internal/model"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	NameGovernanceParameterChanges = "governance_parameter_changes"
+	defaultGovernanceCacheTTL      = 60 * time.Second
+)
+
+var governanceCache struct {
+	sync.Mutex
+	settings  *model.GovernanceSettings
+	expiresAt time.Time
+}
+
+// GovernanceParameters Parameter name stored for each governor configuration event.
+var GovernanceParameters = map[string]string{
+	EventNameVotingDelaySet:         "voting_delay",
+	EventNameVotingPeriodSet:        "voting_period",
+	EventNameProposalThresholdSet:   "proposal_threshold",
+	EventNameQuorumNumeratorUpdated: "quorum_numerator",
+}
+
+// saveGovernanceParameterChange Store the old/new value of a governor configuration event.
+func saveGovernanceParameterChange(ctx context.Context, t Target, evtName string, data model.GovernanceParameterLog, log types.Log, header *types.Header) error {
+	oldValue, newValue := data.Values()
+	if oldValue == nil || newValue == nil {
+		return errors.New(boraLabsErr.FailedParseLogData)
+	}
+
+	m := model.GovernanceParameterChange{
+		Parameter:      GovernanceParameters[evtName],
+		Event:          evtName,
+		OldValue:       oldValue.String(),
+		NewValue:       newValue.String(),
+		BlockNumber:    log.BlockNumber,
+		LogIndex:       log.Index,
+		BlockHash:      log.BlockHash.Hex(),
+		TxHash:         log.TxHash.Hex(),
+		BlockCreatedAt: time.Unix(int64(header.Time), 0),
+	}
+
+	opt := options.Update().SetUpsert(true)
+	_, err := t.Collection(NameGovernanceParameterChanges).UpdateOne(ctx, bson.D{
+		{Key: "tx_hash", Value: m.TxHash},
+		{Key: "log_index", Value: m.LogIndex},
+	}, bson.D{{Key: "$set", Value: m}}, opt)
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
+		return err
+	}
+	util.Log(fmt.Sprintf("Successfully %s [%s -> %s]", evtName, m.OldValue, m.NewValue))
+
+	// the cached settings are outdated now
+	governanceCache.Lock()
+	governanceCache.expiresAt = time.Time{}
+	governanceCache.Unlock()
+	return nil
+}
+
+// GetGovernanceSettings Governor and token settings, cached for governanceCacheTTL.
+func GetGovernanceSettings(ctx context.Context) (model.GovernanceSettings, error) {
+	governanceCache.Lock()
+	defer governanceCache.Unlock()
+
+	if governanceCache.settings != nil && time.Now().Before(governanceCache.expiresAt) {
+		return *governanceCache.settings, nil
+	}
+
+	settings, err := readGovernanceSettings(ctx)
+	if err != nil {
+		if governanceCache.settings != nil { // serve the stale settings rather than failing while the RPC is unavailable
+			log.Printf("Failed read governance settings, serving cached settings :: %v\n", err)
+			return *governanceCache.settings, nil
+		}
+		return settings, err
+	}
+
+	ttl := config.C.GetDuration("governanceCacheTTL")
+	if ttl <= 0 {
+		ttl = defaultGovernanceCacheTTL
+	}
+	governanceCache.settings = &settings
+	governanceCache.expiresAt = time.Now().Add(ttl)
+	return settings, nil
+}
+
+func readGovernanceSettings(ctx context.Context) (settings model.GovernanceSettings, err error) {
+	var out interface{}
+	read := func(c *Contract, method string, dest func(interface{}) bool) {
+		if err != nil {
+			return
+		}
+		if out, err = c.CallView(ctx, method); err != nil {
+			err = errors.New(fmt.Sprintf("%s%v", fmt.Sprintf(boraLabsErr.FailedContractCall, method), err))
+			return
+		}
+		if !dest(out) {
+			err = errors.New(fmt.Sprintf(boraLabsErr.FailedContractCall, method))
+		}
+	}
+	asString := func(v *string) func(interface{}) bool {
+		return func(o interface{}) (ok bool) {
+			*v, ok = o.(string)
+			return
+		}
+	}
+	asNumber := func(v *string) func(interface{}) bool {
+		return func(o interface{}) bool {
+			n, ok := o.(*big.Int)
+			if ok {
+				*v = n.String()
+			}
+			return ok
+		}
+	}
+
+	// governor
+	read(GovCont, "name", asString(&settings.Name))
+	read(GovCont, "version", asString(&settings.Version))
+	read(GovCont, "votingDelay", asNumber(&settings.VotingDelay))
+	read(GovCont, "votingPeriod", asNumber(&settings.VotingPeriod))
+	read(GovCont, "proposalThreshold", asNumber(&settings.ProposalThreshold))
+	read(GovCont, "quorumNumerator", asNumber(&settings.QuorumNumerator))
+	read(GovCont, "quorumDenominator", asNumber(&settings.QuorumDenominator))
+	read(GovCont, "COUNTING_MODE", asString(&settings.CountingMode))
+	read(GovCont, "CLOCK_MODE", asString(&settings.ClockMode))
+	read(GovCont, "token", func(o interface{}) bool {
+		address, ok := o.(common.Address)
+		if ok {
+			settings.Token.Address = address.String()
+		}
+		return ok
+	})
+
+	// token
+	read(DaoCont, "name", asString(&settings.Token.Name))
+	read(DaoCont, "symbol", asString(&settings.Token.Symbol))
+	read(DaoCont, "decimals", func(o interface{}) (ok bool) {
+		settings.Token.Decimals, ok = o.(uint8)
+		return
+	})
+	read(DaoCont, "totalSupply", asNumber(&settings.Token.TotalSupply))
+
+	settings.UpdatedAt = time.Now()
+	return
+}
@@ -0,0 +1,121 @@
+package chain
+
+import (
+	"boralabs/config"
+	"container/list"
+	"context"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/singleflight"
+	"math/big"
+	"strconv"
+	"sync"
+)
+
+const defaultHeaderCacheSize = 4096
+
+type headerKey struct {
+	number uint64
+	hash   common.Hash
+}
+
+// headerCache Bounded LRU cache of block headers shared by all event handlers.
+// generation changes on each invalidation, a header fetched before it is not cached.
+var headerCache = struct {
+	sync.Mutex
+	entries    map[headerKey]*list.Element
+	lru        *list.List
+	generation uint64
+}{
+	entries: make(map[headerKey]*list.Element),
+	lru:     list.New(),
+}
+
+// headerFetches Concurrent misses of the same block share one fetch.
+var headerFetches singleflight.Group
+
+// BlockHeader Header of the block the log was emitted in, looked up by block number and hash.
+// On a miss the canonical header of the block number is fetched without the block body, so a
+// header whose hash differs from blockHash means the log is no longer on the canonical chain.
+func (c *Contract) BlockHeader(ctx context.Context, blockNumber uint64, blockHash common.Hash) (*types.Header, error) {
+	if header, ok := cachedHeader(headerKey{number: blockNumber, hash: blockHash}); ok {
+		return header, nil
+	}
+
+	// the shared fetch outlives a caller giving up, the others still wait for it
+	fetchCtx := context.WithoutCancel(ctx)
+	fetch := headerFetches.DoChan(strconv.FormatUint(blockNumber, 10), func() (any, error) {
+		generation := headerGeneration()
+		header, err := c.HeaderByNumber(fetchCtx, big.NewInt(int64(blockNumber)))
+		if err != nil {
+			return nil, err
+		}
+		cacheHeader(headerKey{number: blockNumber, hash: header.Hash()}, header, generation)
+		return header, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-fetch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*types.Header), nil
+	}
+}
+
+// invalidateHeaders Drop the headers from the given block number onwards, e.g. after a reorg replaced them.
+func invalidateHeaders(from uint64) {
+	headerCache.Lock()
+	defer headerCache.Unlock()
+
+	headerCache.generation++
+	for key, elem := range headerCache.entries {
+		if key.number >= from {
+			headerCache.lru.Remove(elem)
+			delete(headerCache.entries, key)
+		}
+	}
+}
+
+func headerGeneration() uint64 {
+	headerCache.Lock()
+	defer headerCache.Unlock()
+	return headerCache.generation
+}
+
+func cachedHeader(key headerKey) (*types.Header, bool) {
+	headerCache.Lock()
+	defer headerCache.Unlock()
+
+	elem, ok := headerCache.entries[key]
+	if !ok {
+		return nil, false
+	}
+	headerCache.lru.MoveToFront(elem)
+	return elem.Value.(*types.Header), true
+}
+
+func cacheHeader(key headerKey, header *types.Header, generation uint64) {
+	headerCache.Lock()
+	defer headerCache.Unlock()
+
+	if generation != headerCache.generation { // fetched before an invalidation, possibly an orphaned header
+		return
+	}
+	if elem, ok := headerCache.entries[key]; ok {
+		headerCache.lru.MoveToFront(elem)
+		return
+	}
+	headerCache.entries[key] = headerCache.lru.PushFront(header)
+
+	size := config.C.GetInt("headerCacheSize")
+	if size <= 0 {
+		size = defaultHeaderCacheSize
+	}
+	for headerCache.lru.Len() > size {
+		oldest := headerCache.lru.Back()
+		header := headerCache.lru.Remove(oldest).(*types.Header)
+		delete(headerCache.entries, headerKey{number: header.Number.Uint64(), hash: header.Hash()})
+	}
+}
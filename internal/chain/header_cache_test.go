@@ -0,0 +1,98 @@
+package chain
+
+import (
+	"boralabs/config"
+	"container/list"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+	"testing"
+)
+
+func resetHeaderCache() {
+	headerCache.Lock()
+	defer headerCache.Unlock()
+	headerCache.entries = make(map[headerKey]*list.Element)
+	headerCache.lru = list.New()
+	headerCache.generation = 0
+}
+
+func testHeader(number uint64) (headerKey, *types.Header) {
+	header := &types.Header{Number: big.NewInt(int64(number)), Difficulty: big.NewInt(0)}
+	return headerKey{number: number, hash: header.Hash()}, header
+}
+
+func TestHeaderCacheInvalidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		cached     []uint64
+		invalidate uint64
+		want       map[uint64]bool
+	}{
+		{"drops the blocks from the number on", []uint64{1, 2, 3, 4, 5}, 3, map[uint64]bool{1: true, 2: true, 3: false, 4: false, 5: false}},
+		{"from the first block drops all", []uint64{1, 2}, 1, map[uint64]bool{1: false, 2: false}},
+		{"past the last block keeps all", []uint64{1, 2}, 3, map[uint64]bool{1: true, 2: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetHeaderCache()
+			for _, number := range tt.cached {
+				key, header := testHeader(number)
+				cacheHeader(key, header, headerGeneration())
+			}
+			invalidateHeaders(tt.invalidate)
+			for number, want := range tt.want {
+				key, _ := testHeader(number)
+				if _, ok := cachedHeader(key); ok != want {
+					t.Errorf("block %d cached = %v, want %v", number, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaderCacheGeneration(t *testing.T) {
+	tests := []struct {
+		name        string
+		invalidated bool // a rollback ran between the start of the fetch and its end
+		want        bool
+	}{
+		{"fetched in the current generation", false, true},
+		{"fetched before an invalidation", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetHeaderCache()
+			generation := headerGeneration()
+			if tt.invalidated {
+				invalidateHeaders(100)
+			}
+			key, header := testHeader(7)
+			cacheHeader(key, header, generation)
+			if _, ok := cachedHeader(key); ok != tt.want {
+				t.Errorf("cached = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeaderCacheEviction(t *testing.T) {
+	config.C.Set("headerCacheSize", 2)
+	defer config.C.Set("headerCacheSize", 0)
+	resetHeaderCache()
+
+	for _, number := range []uint64{1, 2} {
+		key, header := testHeader(number)
+		cacheHeader(key, header, headerGeneration())
+	}
+	key1, _ := testHeader(1)
+	cachedHeader(key1) // block 2 is now the least recently used
+	key3, header3 := testHeader(3)
+	cacheHeader(key3, header3, headerGeneration())
+
+	for number, want := range map[uint64]bool{1: true, 2: false, 3: true} {
+		key, _ := testHeader(number)
+		if _, ok := cachedHeader(key); ok != want {
+			t.Errorf("block %d cached = %v, want %v", number, ok, want)
+		}
+	}
+}
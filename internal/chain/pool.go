@@ -0,0 +1,332 @@
+package chain
+
+import (
+	"boralabs/config"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/metrics"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHealthCheckInterval = 15 * time.Second
+	defaultMaxBlockLag         = 10
+	maxConsecutiveErrors       = 3
+)
+
+// Pool RPC clients shared by every contract.
+var Pool *ClientPool
+
+// ClientPool Routes each RPC call to the healthiest endpoint and fails over to the next one.
+type ClientPool struct {
+	endpoints []*endpoint
+}
+
+type endpoint struct {
+	sync.Mutex
+	url               string
+	ecl               *ethclient.Client
+	latency           time.Duration // moving average of the successful calls
+	consecutiveErrors int
+	head              uint64
+	lastError         string
+	checkedAt         time.Time
+}
+
+// EndpointStats Health of an RPC endpoint.
+type EndpointStats struct {
+	URL               string        `json:"url"`
+	Healthy           bool          `json:"healthy"`
+	Latency           time.Duration `json:"latency"`
+	ConsecutiveErrors int           `json:"consecutive_errors"`
+	Head              uint64        `json:"head"`
+	BlockLag          uint64        `json:"block_lag"`
+	LastError         string        `json:"last_error,omitempty"`
+	CheckedAt         time.Time     `json:"checked_at"`
+}
+
+// rpcEndpoints The rpcEndpoints list, or the single rpcEndpoint.
+func rpcEndpoints() []string {
+	urls := config.C.GetStringSlice("rpcEndpoints")
+	if len(urls) == 0 && config.C.GetString("rpcEndpoint") != "" {
+		urls = []string{config.C.GetString("rpcEndpoint")}
+	}
+	return urls
+}
+
+// NewClientPool Pool of the endpoints. Endpoints are dialed on first use, so a node that is down does not fail the start.
+func NewClientPool(urls []string) (*ClientPool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New(boraLabsErr.EmptyConfigValue)
+	}
+	p := &ClientPool{}
+	for _, url := range urls {
+		p.endpoints = append(p.endpoints, &endpoint{url: url})
+	}
+	return p, nil
+}
+
+// HealthCheck Refresh latency and head block of every endpoint periodically until the context is done.
+func (p *ClientPool) HealthCheck(ctx context.Context) {
+	interval := config.C.GetDuration("rpcHealthCheckInterval")
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *ClientPool) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			var head uint64
+			err := ep.call(func(ecl *ethclient.Client) (err error) {
+				head, err = ecl.BlockNumber(ctx)
+				return
+			})
+			ep.Lock()
+			if err == nil {
+				ep.head = head
+			}
+			ep.checkedAt = time.Now()
+			ep.Unlock()
+		}(ep)
+	}
+	wg.Wait()
+}
+
+// Stats Health of every endpoint, in routing order.
+func (p *ClientPool) Stats() []EndpointStats {
+	maxHead := p.maxHead()
+	stats := make([]EndpointStats, 0, len(p.endpoints))
+	for _, ep := range p.ranked() {
+		ep.Lock()
+		s := EndpointStats{
+			URL:               ep.url,
+			Healthy:           ep.healthy(maxHead),
+			Latency:           ep.latency,
+			ConsecutiveErrors: ep.consecutiveErrors,
+			Head:              ep.head,
+			LastError:         ep.lastError,
+			CheckedAt:         ep.checkedAt,
+		}
+		if maxHead > ep.head {
+			s.BlockLag = maxHead - ep.head
+		}
+		ep.Unlock()
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+func (p *ClientPool) maxHead() (head uint64) {
+	for _, ep := range p.endpoints {
+		ep.Lock()
+		head = max(head, ep.head)
+		ep.Unlock()
+	}
+	return
+}
+
+// ranked Healthy endpoints first, each group ordered by latency. An endpoint without a successful call yet
+// has no latency, it comes after the measured ones of its group.
+func (p *ClientPool) ranked() []*endpoint {
+	maxHead := p.maxHead()
+	type rank struct {
+		ep      *endpoint
+		healthy bool
+		latency time.Duration
+	}
+	ranks := make([]rank, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		ep.Lock()
+		ranks = append(ranks, rank{ep: ep, healthy: ep.healthy(maxHead), latency: ep.latency})
+		ep.Unlock()
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if ranks[i].healthy != ranks[j].healthy {
+			return ranks[i].healthy
+		}
+		if (ranks[i].latency == 0) != (ranks[j].latency == 0) {
+			return ranks[j].latency == 0
+		}
+		return ranks[i].latency < ranks[j].latency
+	})
+
+	eps := make([]*endpoint, 0, len(ranks))
+	for _, r := range ranks {
+		eps = append(eps, r.ep)
+	}
+	return eps
+}
+
+// do Run the call on the healthiest endpoint, failing over to the next one when the node is unavailable.
+// An error answered by the node itself, e.g. a reverted call, is returned as is.
+func (p *ClientPool) do(method string, f func(ecl *ethclient.Client) error) error {
+	return p.try(method, isNodeErr, f)
+}
+
+// doBlockRead Like do, a block the endpoint does not know is also read from the next one.
+// A healthy endpoint may lag up to rpcMaxBlockLag blocks behind the others.
+func (p *ClientPool) doBlockRead(method string, f func(ecl *ethclient.Client) error) error {
+	return p.try(method, func(err error) bool {
+		return errors.Is(err, ethereum.NotFound) || isNodeErr(err)
+	}, f)
+}
+
+func (p *ClientPool) try(method string, failover func(err error) bool, f func(ecl *ethclient.Client) error) (err error) {
+	defer func(startedAt time.Time) { metrics.ObserveRPC(method, startedAt, err) }(time.Now())
+	for _, ep := range p.ranked() {
+		if err = ep.call(f); err == nil || !failover(err) {
+			return
+		}
+		log.Printf("RPC endpoint %s failed, failing over :: %v\n", ep.url, err)
+	}
+	return
+}
+
+// healthy Whether the endpoint answers and follows the chain head. The lock must be held.
+func (ep *endpoint) healthy(maxHead uint64) bool {
+	maxLag := config.C.GetUint64("rpcMaxBlockLag")
+	if maxLag == 0 {
+		maxLag = defaultMaxBlockLag
+	}
+	return ep.consecutiveErrors < maxConsecutiveErrors && ep.head+maxLag >= maxHead
+}
+
+func (ep *endpoint) client() (*ethclient.Client, error) {
+	ep.Lock()
+	defer ep.Unlock()
+	if ep.ecl != nil {
+		return ep.ecl, nil
+	}
+	ecl, err := ethclient.DialContext(context.Background(), ep.url)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Dialing error %v", err))
+	}
+	ep.ecl = ecl
+	return ecl, nil
+}
+
+func (ep *endpoint) call(f func(ecl *ethclient.Client) error) error {
+	ecl, err := ep.client()
+	if err == nil {
+		startedAt := time.Now()
+		err = f(ecl)
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err // says nothing about the endpoint
+		}
+		if err == nil || !isNodeErr(err) {
+			elapsed := time.Since(startedAt)
+			ep.Lock()
+			if ep.latency == 0 {
+				ep.latency = elapsed
+			} else {
+				ep.latency = (ep.latency*4 + elapsed) / 5
+			}
+			ep.consecutiveErrors = 0
+			ep.Unlock()
+			return err
+		}
+	}
+
+	ep.Lock()
+	ep.consecutiveErrors++
+	ep.lastError = err.Error()
+	ep.Unlock()
+	return err
+}
+
+// isNodeErr Whether the error comes from an unavailable node rather than from the request.
+// A throttled endpoint is failed over, a cancelled or timed out request is not held against the endpoint.
+func isNodeErr(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if IsRateLimitErr(err) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false // the node answered the request
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return true
+	}
+	return !IsRangeTooLargeErr(err) && !errors.Is(err, ethereum.NotFound)
+}
+
+func (p *ClientPool) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = p.do("eth_getCode", func(ecl *ethclient.Client) (err error) {
+		code, err = ecl.CodeAt(ctx, contract, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = p.do("eth_call", func(ecl *ethclient.Client) (err error) {
+		result, err = ecl.CallContract(ctx, call, blockNumber)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) FilterLogs(ctx context.Context, q ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = p.do("eth_getLogs", func(ecl *ethclient.Client) (err error) {
+		logs, err = ecl.FilterLogs(ctx, q)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = p.doBlockRead("eth_getBlockByNumber", func(ecl *ethclient.Client) (err error) {
+		header, err = ecl.HeaderByNumber(ctx, number)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) BlockNumber(ctx context.Context) (blockNumber uint64, err error) {
+	err = p.do("eth_blockNumber", func(ecl *ethclient.Client) (err error) {
+		blockNumber, err = ecl.BlockNumber(ctx)
+		return
+	})
+	return
+}
+
+func (p *ClientPool) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = p.do("eth_chainId", func(ecl *ethclient.Client) (err error) {
+		chainID, err = ecl.ChainID(ctx)
+		return
+	})
+	return
+}
@@ -0,0 +1,324 @@
+package chain
+
+import (
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"math/big"
+)
+
+const (
+	rebuildSuffix = "_rebuild"
+	backupSuffix  = "_backup"
+)
+
+// Target Collections the log handlers write to and whether they may call the chain.
+// The zero Target is the live collections, with the chain.
+type Target struct {
+	suffix  string
+	offline bool
+}
+
+// rebuildTarget Rebuild replays the archive into the _rebuild collections without calling the governor and token,
+// the snapshot values (total supply, quorum, state) are taken from the seeded proposals.
+var rebuildTarget = Target{suffix: rebuildSuffix, offline: true}
+
+// Collection Collection derived from the logs.
+func (t Target) Collection(name string) *mongo2.Collection {
+	return mongodb.DB.Collection(name + t.suffix)
+}
+
+// projections Collections derived from the logs, rebuilt by Rebuild.
+func projections() []string {
+	names := []string{
+		NameProposals,
+		NameVotes,
+		LogCollectionName(EventNameProposalCreated),
+		LogCollectionName(EventNameVoteCast),
+		LogCollectionName(EventNameProposalCanceled),
+		LogCollectionName(EventNameProposalExecuted),
+		NameDelegations,
+		NameDelegateVotesHistory,
+		NameGovernanceParameterChanges,
+	}
+	for _, cont := range []*Contract{GovCont, DaoCont} {
+		for _, evtName := range cont.ArchiveEvents() {
+			if !HasHandler(evtName) {
+				names = append(names, LogCollectionName(evtName))
+			}
+		}
+	}
+	return names
+}
+
+// archivedSources Collections derived from the logs with the events they are derived from.
+func archivedSources() map[string][]string {
+	sources := map[string][]string{
+		LogCollectionName(EventNameProposalCreated):  {EventNameProposalCreated},
+		LogCollectionName(EventNameVoteCast):         {EventNameVoteCast},
+		LogCollectionName(EventNameProposalCanceled): {EventNameProposalCanceled},
+		LogCollectionName(EventNameProposalExecuted): {EventNameProposalExecuted},
+		NameDelegations:          {EventNameDelegateChanged},
+		NameDelegateVotesHistory: {EventNameDelegateVotesChanged},
+	}
+	for evtName := range GovernanceParameters {
+		sources[NameGovernanceParameterChanges] = append(sources[NameGovernanceParameterChanges], evtName)
+	}
+	for _, cont := range []*Contract{GovCont, DaoCont} {
+		for _, evtName := range cont.ArchiveEvents() {
+			if !HasHandler(evtName) {
+				sources[LogCollectionName(evtName)] = []string{evtName}
+			}
+		}
+	}
+	return sources
+}
+
+// checkArchive Refuse to rebuild when a collection holds logs older than the archive of their events,
+// e.g. when they were collected before the archive existed. The swap would drop them.
+func checkArchive(ctx context.Context) error {
+	for name, evtNames := range archivedSources() {
+		liveFrom, found, err := mongodb.MinBlockNumber(ctx, name, bson.D{})
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		archivedFrom, found, err := mongodb.MinBlockNumber(ctx, mongodb.CollectionEventLogs, bson.D{
+			{Key: "event", Value: bson.D{{Key: "$in", Value: evtNames}}},
+			{Key: "removed", Value: false},
+		})
+		if err != nil {
+			return err
+		}
+		if !found || archivedFrom > liveFrom {
+			return errors.New(fmt.Sprintf(boraLabsErr.IncompleteArchive, name, liveFrom, evtNames, archivedFrom))
+		}
+	}
+	return nil
+}
+
+// Rebuild Replay the archived logs in block/log index order into fresh projection collections
+// and swap them in once the replay finished. The chain is not accessed.
+// The off-chain fields of the proposals (title, description, ...) are kept.
+// holdsLease tells whether the indexer is still kept out, the live collections are not swapped once it returns false.
+func Rebuild(ctx context.Context, holdsLease func() bool) error {
+	if err := checkArchive(ctx); err != nil {
+		return err
+	}
+
+	names := projections()
+	for _, name := range names {
+		if err := rebuildTarget.Collection(name).Drop(ctx); err != nil {
+			return err
+		}
+		if err := mongodb.DB.CreateCollection(ctx, name+rebuildSuffix); err != nil {
+			return err
+		}
+	}
+	// the swap keeps the indexes of the rebuilt collections
+	if err := mongodb.CreateDelegationIndexes(ctx, rebuildSuffix); err != nil {
+		return err
+	}
+	if err := seedProposals(ctx); err != nil {
+		return err
+	}
+	if err := seedVotes(ctx); err != nil {
+		return err
+	}
+	if err := mongodb.CreateVoteIndexes(ctx, rebuildSuffix); err != nil {
+		return err
+	}
+
+	opt := options.Find().SetSort(bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}})
+	cursor, err := mongodb.DB.Collection(mongodb.CollectionEventLogs).Find(ctx, bson.D{{Key: "removed", Value: false}}, opt)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var replayed, failed int
+	for cursor.Next(ctx) {
+		var archived model.ArchivedLog
+		if err = cursor.Decode(&archived); err != nil {
+			return err
+		}
+		if err = replay(ctx, archived); err != nil {
+			failed++
+			log.Printf("Failed replay :: %s [%s:%d] :: %v\n", archived.Event, archived.TxHash, archived.LogIndex, err)
+			continue
+		}
+		replayed++
+	}
+	if err = cursor.Err(); err != nil {
+		return err
+	}
+	// seeded votes without a replayed log
+	if _, err = rebuildTarget.Collection(NameVotes).DeleteMany(ctx, bson.D{{Key: "tx_hash", Value: bson.D{{Key: "$exists", Value: false}}}}); err != nil {
+		return err
+	}
+
+	// proposals submitted through the API during the replay
+	if err = mergeNewProposals(ctx); err != nil {
+		return err
+	}
+	if !holdsLease() {
+		return errors.New("lost the indexer lease, the live collections were not replaced")
+	}
+	if err = swapProjections(context.WithoutCancel(ctx), names); err != nil {
+		return err
+	}
+	util.Log(fmt.Sprintf("Rebuild finished :: %d logs replayed / %d failed", replayed, failed))
+	return nil
+}
+
+// renameCollection, dropCollection Collection commands of the swap, replaced in the tests.
+var (
+	renameCollection = mongodb.RenameCollection
+	dropCollection   = func(ctx context.Context, name string) error {
+		return mongodb.DB.Collection(name).Drop(ctx)
+	}
+)
+
+// swapProjections Replace the live collections by the rebuilt ones, one after the other.
+// Each live collection is renamed to its _backup first. When a rename fails the collections already replaced
+// get their backups back, so the live collections are either all rebuilt or all left as they were.
+func swapProjections(ctx context.Context, names []string) error {
+	var backedUp, swapped []string
+	restore := func() {
+		for _, name := range swapped {
+			if err := renameCollection(ctx, name, name+rebuildSuffix, true); err != nil {
+				util.ErrorLog(errors.New(fmt.Sprintf("Failed restore %s, the rebuilt collection stays live :: %v", name, err)))
+			}
+		}
+		for _, name := range backedUp {
+			if err := renameCollection(ctx, name+backupSuffix, name, true); err != nil {
+				util.ErrorLog(errors.New(fmt.Sprintf("Failed restore %s, the live data is in %s :: %v", name, name+backupSuffix, err)))
+			}
+		}
+	}
+
+	for _, name := range names {
+		if err := dropCollection(ctx, name+backupSuffix); err != nil { // left over by an earlier rebuild
+			restore()
+			return err
+		}
+		err := renameCollection(ctx, name, name+backupSuffix, false)
+		if err != nil && !mongodb.IsNamespaceNotFoundErr(err) {
+			restore()
+			return errors.New(fmt.Sprintf("Failed back up %s :: %v", name, err))
+		}
+		if err == nil {
+			backedUp = append(backedUp, name)
+		}
+		if err = renameCollection(ctx, name+rebuildSuffix, name, true); err != nil {
+			restore()
+			return errors.New(fmt.Sprintf("Failed swap %s :: %v", name, err))
+		}
+		swapped = append(swapped, name)
+	}
+
+	for _, name := range backedUp {
+		if err := dropCollection(ctx, name+backupSuffix); err != nil {
+			log.Printf("Failed drop %s :: %v\n", name+backupSuffix, err)
+		}
+	}
+	return nil
+}
+
+// seedProposals Copy the proposals without the values derived from the logs.
+func seedProposals(ctx context.Context) error {
+	cursor, err := mongodb.DB.Collection(NameProposals).Aggregate(ctx, mongo2.Pipeline{
+		{{Key: "$unset", Value: bson.A{
+			"block_number", "block_hash",
+			"total_voting_power", "voting_ratio",
+			"for_votes", "against_votes", "abstain_votes", "quorum_reached",
+			"canceled_tx_hash", "canceled_at", "executed_tx_hash", "executed_at",
+		}}},
+		{{Key: "$out", Value: NameProposals + rebuildSuffix}},
+	})
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
+
+// mergeNewProposals Copy the proposals created since they were seeded.
+func mergeNewProposals(ctx context.Context) error {
+	_, err := rebuildTarget.Collection(NameProposals).Indexes().CreateOne(ctx, mongo2.IndexModel{
+		Keys:    bson.D{{Key: "proposal_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	cursor, err := mongodb.DB.Collection(NameProposals).Aggregate(ctx, mongo2.Pipeline{
+		{{Key: "$merge", Value: bson.D{
+			{Key: "into", Value: NameProposals + rebuildSuffix},
+			{Key: "on", Value: "proposal_id"},
+			{Key: "whenMatched", Value: "keepExisting"},
+			{Key: "whenNotMatched", Value: "insert"},
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
+
+// seedVotes Copy the ids of the votes, the replayed VoteCast logs keep them.
+// A vote inserted twice keeps its first id.
+func seedVotes(ctx context.Context) error {
+	cursor, err := mongodb.DB.Collection(NameVotes).Aggregate(ctx, mongo2.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "proposal_id", Value: "$proposal_id"},
+				{Key: "wallet_address", Value: "$wallet_address"},
+			}},
+			{Key: "id", Value: bson.D{{Key: "$min", Value: "$id"}}},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "id", Value: 1},
+			{Key: "proposal_id", Value: "$_id.proposal_id"},
+			{Key: "wallet_address", Value: "$_id.wallet_address"},
+		}}},
+		{{Key: "$out", Value: NameVotes + rebuildSuffix}},
+	})
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
+
+// replay Run the handler of the archived log with the block data stored in the archive.
+func replay(ctx context.Context, archived model.ArchivedLog) error {
+	eLog := archived.Log()
+	cont, err := ContractByAddress(eLog.Address)
+	if err != nil {
+		return err
+	}
+	evt := Event{Cont: cont, Target: rebuildTarget}
+	if evt, err = evt.New(archived.Event); err != nil {
+		return err
+	}
+	if err = evt.Decode(eLog); err != nil {
+		return err
+	}
+
+	header := &types.Header{
+		Number: big.NewInt(int64(archived.BlockNumber)),
+		Time:   uint64(archived.BlockCreatedAt.Unix()),
+	}
+	return evt.handler()(ctx, &evt, "", eLog, header)
+}
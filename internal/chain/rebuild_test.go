@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"reflect"
+	"testing"
+)
+
+// fakeCollections Collections by name with the data they hold, the renames fail at the given step.
+type fakeCollections struct {
+	data   map[string]string
+	steps  int
+	failAt int // 1-based rename failing, 0 for none
+}
+
+func (f *fakeCollections) rename(_ context.Context, source, target string, dropTarget bool) error {
+	f.steps++
+	if f.steps == f.failAt {
+		return errors.New("rename failed")
+	}
+	data, ok := f.data[source]
+	if !ok {
+		return mongo2.CommandError{Code: 26, Message: fmt.Sprintf("source namespace %s does not exist", source)}
+	}
+	if _, exists := f.data[target]; exists && !dropTarget {
+		return mongo2.CommandError{Code: 48, Message: fmt.Sprintf("target namespace %s exists", target)}
+	}
+	f.data[target] = data
+	delete(f.data, source)
+	return nil
+}
+
+func (f *fakeCollections) drop(_ context.Context, name string) error {
+	delete(f.data, name)
+	return nil
+}
+
+func TestSwapProjections(t *testing.T) {
+	live := map[string]string{"proposals": "live proposals", "votes": "live votes"}
+	rebuilt := map[string]string{"proposals": "rebuilt proposals", "votes": "rebuilt votes", "delegations": "rebuilt delegations"}
+	tests := []struct {
+		name    string
+		failAt  int
+		wantErr bool
+		want    map[string]string
+	}{
+		{
+			name: "all collections replaced, the backups dropped",
+			want: rebuilt,
+		},
+		{
+			name: "backup of the first collection fails", failAt: 1, wantErr: true,
+			want: live,
+		},
+		{
+			name: "swap of the first collection fails", failAt: 2, wantErr: true,
+			want: live,
+		},
+		{
+			name: "backup of the second collection fails", failAt: 3, wantErr: true,
+			want: live,
+		},
+		{
+			name: "swap of the last collection fails", failAt: 6, wantErr: true,
+			want: live,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeCollections{data: map[string]string{}, failAt: tt.failAt}
+			for name, data := range live {
+				f.data[name] = data
+			}
+			for name, data := range rebuilt {
+				f.data[name+rebuildSuffix] = data
+			}
+			f.data["votes"+backupSuffix] = "left over by an earlier rebuild"
+			defer func(rename func(context.Context, string, string, bool) error, drop func(context.Context, string) error) {
+				renameCollection, dropCollection = rename, drop
+			}(renameCollection, dropCollection)
+			renameCollection, dropCollection = f.rename, f.drop
+
+			err := swapProjections(context.Background(), []string{"proposals", "votes", "delegations"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("swapProjections() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := map[string]string{}
+			for _, name := range []string{"proposals", "votes", "delegations"} {
+				if data, ok := f.data[name]; ok {
+					got[name] = data
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("live collections = %v, want %v", got, tt.want)
+			}
+			for _, name := range []string{"proposals", "votes", "delegations"} {
+				if _, ok := f.data[name+backupSuffix]; ok && !tt.wantErr {
+					t.Errorf("backup of %s not dropped", name)
+				}
+				if data := f.data[name+rebuildSuffix]; tt.wantErr && data != rebuilt[name] {
+					t.Errorf("rebuilt %s = %q, want %q", name, data, rebuilt[name])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package chain
+
+import (
+	"boralabs/config"
+	"boralabs/internal/model"
+	boraLabsErr "boralabs/pkg/error"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/iancoleman/strcase"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// Handler Persist a decoded log. The header is the header of the block the log was emitted in.
+type Handler func(ctx context.Context, e *Event, proposalID string, log types.Log, header *types.Header) error
+
+type registryEntry struct {
+	newOut  func() any
+	handler Handler
+}
+
+// registry Decoder output and handler of the events with custom persistence.
+// Any other event of the contract abi is decoded into a map and stored by saveArchivedLog.
+var registry = map[string]registryEntry{}
+
+// Register Register the decoder output and the persistence handler of an event.
+func Register(evtName string, newOut func() any, handler Handler) {
+	registry[evtName] = registryEntry{newOut: newOut, handler: handler}
+}
+
+func init() {
+	Register(EventNameProposalCreated, func() any { return &model.ProposalCreatedLog{} }, saveProposalCreated)
+	Register(EventNameVoteCast, func() any { return &model.VoteCastLog{} }, saveVoteCast)
+	Register(EventNameProposalCanceled, func() any { return &model.ProposalCanceledLog{} }, func(ctx context.Context, e *Event, proposalID string, log types.Log, header *types.Header) error {
+		data, ok := e.Out.(*model.ProposalCanceledLog)
+		if !ok {
+			return errors.New(boraLabsErr.FailedParseLogData)
+		}
+		return saveProposalFinalState(ctx, e.Target, e.Name, proposalID, data.ProposalId, ProposalStateCanceled, log, header)
+	})
+	Register(EventNameProposalExecuted, func() any { return &model.ProposalExecutedLog{} }, func(ctx context.Context, e *Event, proposalID string, log types.Log, header *types.Header) error {
+		data, ok := e.Out.(*model.ProposalExecutedLog)
+		if !ok {
+			return errors.New(boraLabsErr.FailedParseLogData)
+		}
+		return saveProposalFinalState(ctx, e.Target, e.Name, proposalID, data.ProposalId, ProposalStateExecuted, log, header)
+	})
+	Register(EventNameDelegateChanged, func() any { return &model.DelegateChangedLog{} }, func(ctx context.Context, e *Event, _ string, log types.Log, header *types.Header) error {
+		data, ok := e.Out.(*model.DelegateChangedLog)
+		if !ok {
+			return errors.New(boraLabsErr.FailedParseLogData)
+		}
+		return saveDelegation(ctx, e.Target, data, log, header)
+	})
+	Register(EventNameDelegateVotesChanged, func() any { return &model.DelegateVotesChangedLog{} }, func(ctx context.Context, e *Event, _ string, log types.Log, header *types.Header) error {
+		data, ok := e.Out.(*model.DelegateVotesChangedLog)
+		if !ok {
+			return errors.New(boraLabsErr.FailedParseLogData)
+		}
+		return saveDelegateVotes(ctx, e.Target, data, log, header)
+	})
+	Register(EventNameVotingDelaySet, func() any { return &model.VotingDelaySetLog{} }, saveGovernanceParameterLog)
+	Register(EventNameVotingPeriodSet, func() any { return &model.VotingPeriodSetLog{} }, saveGovernanceParameterLog)
+	Register(EventNameProposalThresholdSet, func() any { return &model.ProposalThresholdSetLog{} }, saveGovernanceParameterLog)
+	Register(EventNameQuorumNumeratorUpdated, func() any { return &model.QuorumNumeratorUpdatedLog{} }, saveGovernanceParameterLog)
+}
+
+func saveGovernanceParameterLog(ctx context.Context, e *Event, _ string, log types.Log, header *types.Header) error {
+	data, ok := e.Out.(model.GovernanceParameterLog)
+	if !ok {
+		return errors.New(boraLabsErr.FailedParseLogData)
+	}
+	return saveGovernanceParameterChange(ctx, e.Target, e.Name, data, log, header)
+}
+
+// handler Registered handler of the event, saveArchivedLog for the events without one.
+func (e *Event) handler() Handler {
+	if entry, has := registry[e.Name]; has {
+		return entry.handler
+	}
+	return saveArchivedLog
+}
+
+// HasHandler Whether the event has a custom persistence handler.
+func HasHandler(evtName string) bool {
+	_, has := registry[evtName]
+	return has
+}
+
+// ArchiveEvents Events of the contract without a custom handler that are collected and archived by the default handler.
+func (c *Contract) ArchiveEvents() []string {
+	return config.C.GetStringSlice("archiveEvents." + c.name)
+}
+
+// validateArchiveEvents Fail when an archived event is not declared in the ABI of the contract.
+func (c *Contract) validateArchiveEvents() error {
+	for _, evtName := range c.ArchiveEvents() {
+		if _, has := c.events[evtName]; !has {
+			return errors.New(fmt.Sprintf(boraLabsErr.InvalidEventName, fmt.Sprintf("%s in archiveEvents.%s", evtName, c.name)))
+		}
+	}
+	return nil
+}
+
+// logCollection Collection the logs of the event are stored in.
+func (t Target) logCollection(evtName string) *mongo2.Collection {
+	return t.Collection(LogCollectionName(evtName))
+}
+
+// LogCollectionName Name of the collection the logs of the event are stored in.
+func LogCollectionName(evtName string) string {
+	return fmt.Sprintf("%s_logs", strcase.ToSnake(evtName))
+}
+
+// saveArchivedLog Default handler, store the generically decoded arguments keyed by tx hash and log index.
+func saveArchivedLog(ctx context.Context, e *Event, _ string, log types.Log, header *types.Header) error {
+	args, err := e.args(log)
+	if err != nil {
+		return Permanent(errors.New(fmt.Sprintf(boraLabsErr.FailedParseLogData, err)))
+	}
+	m := model.EventLog{
+		Event:          e.Name,
+		Address:        log.Address.Hex(),
+		Args:           args,
+		TxHash:         log.TxHash.Hex(),
+		LogIndex:       log.Index,
+		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash.Hex(),
+		BlockCreatedAt: time.Unix(int64(header.Time), 0),
+		UpdatedAt:      time.Now(),
+	}
+
+	opt := options.Update().SetUpsert(true)
+	_, err = e.Target.logCollection(e.Name).UpdateOne(ctx, bson.D{
+		{Key: "tx_hash", Value: m.TxHash},
+		{Key: "log_index", Value: m.LogIndex},
+	}, bson.D{{Key: "$set", Value: m}}, opt)
+	if err != nil {
+		return errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
+	}
+	return nil
+}
+
+// toBSONValue Convert a value unpacked by the abi into a value MongoDB can store and query.
+// Big integers become decimal strings, addresses, hashes and bytes become hex strings and tuples become documents.
+func toBSONValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch value := v.Interface().(type) {
+	case *big.Int:
+		if value == nil {
+			return nil
+		}
+		return value.String()
+	case common.Address:
+		return value.Hex()
+	case common.Hash:
+		return value.Hex()
+	case []byte:
+		return hexutil.Encode(value)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toBSONValue(v.Elem())
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 { // bytesN
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		arr := bson.A{}
+		for i := 0; i < v.Len(); i++ {
+			arr = append(arr, toBSONValue(v.Index(i)))
+		}
+		return arr
+	case reflect.Struct:
+		doc := bson.M{}
+		for i := 0; i < v.NumField(); i++ {
+			doc[strcase.ToSnake(v.Type().Field(i).Name)] = toBSONValue(v.Field(i))
+		}
+		return doc
+	}
+	return v.Interface()
+}
@@ -0,0 +1,211 @@
+package chain
+
+import (
+	"boralabs/config"
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	"boralabs/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"math/big"
+	"time"
+)
+
+const (
+	NameIndexedBlocks      = mongodb.CollectionIndexedBlocks
+	defaultReorgCheckDepth = 128
+)
+
+// RecordBlock Remember the hash of a processed block so a later reorg can be detected.
+func RecordBlock(ctx context.Context, blockNumber uint64, blockHash string) error {
+	opt := options.Update().SetUpsert(true)
+	_, err := mongodb.DB.Collection(NameIndexedBlocks).UpdateOne(ctx, bson.D{
+		{Key: "block_number", Value: blockNumber},
+	}, bson.D{{Key: "$set", Value: model.IndexedBlock{
+		BlockNumber: blockNumber,
+		BlockHash:   blockHash,
+		UpdatedAt:   time.Now(),
+	}}}, opt)
+	return err
+}
+
+// CheckReorg Compare the recorded block hashes with the canonical chain and roll back the data derived from orphaned blocks.
+func CheckReorg(ctx context.Context) error {
+	depth := config.C.GetInt64("reorgCheckDepth")
+	if depth <= 0 {
+		depth = defaultReorgCheckDepth
+	}
+
+	opt := options.Find().SetSort(bson.D{{Key: "block_number", Value: -1}}).SetLimit(depth)
+	cursor, err := mongodb.DB.Collection(NameIndexedBlocks).Find(ctx, bson.D{}, opt)
+	if err != nil {
+		return err
+	}
+	var blocks []model.IndexedBlock
+	if err = cursor.All(ctx, &blocks); err != nil {
+		return err
+	}
+
+	// walk down from the newest block, every block below a canonical one is canonical as well
+	var forkFrom uint64
+	for _, b := range blocks {
+		header, err := GovCont.HeaderByNumber(ctx, big.NewInt(int64(b.BlockNumber)))
+		if err != nil {
+			return err
+		}
+		if header.Hash().Hex() == b.BlockHash {
+			break
+		}
+		forkFrom = b.BlockNumber
+	}
+	if forkFrom == 0 {
+		// blocks below the checked window are never compared again
+		if len(blocks) == int(depth) {
+			_, err = mongodb.DB.Collection(NameIndexedBlocks).DeleteMany(ctx, bson.D{
+				{Key: "block_number", Value: bson.D{{Key: "$lt", Value: blocks[len(blocks)-1].BlockNumber}}},
+			})
+		}
+		return err
+	}
+
+	util.ErrorLog(errors.New(fmt.Sprintf("Chain reorg detected :: rollback from block %d", forkFrom)))
+	return Rollback(ctx, forkFrom)
+}
+
+// Rollback Remove the proposals/votes/delegation data derived from the blocks from the given block number onwards.
+// The collector indexes the canonical logs of those blocks again on its next run.
+func Rollback(ctx context.Context, from uint64) error {
+	ctx = context.WithoutCancel(ctx) // a started rollback is finished, even on shutdown
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	invalidateHeaders(from)
+	fromFilter := bson.D{{Key: "block_number", Value: bson.D{{Key: "$gte", Value: from}}}}
+
+	// votes
+	votedProposals, err := mongodb.DB.Collection(NameVotes).Distinct(ctx, "proposal_id", fromFilter)
+	if err != nil {
+		return err
+	}
+	for _, coll := range []string{NameVotes, "vote_cast_logs"} {
+		if _, err = mongodb.DB.Collection(coll).DeleteMany(ctx, fromFilter); err != nil {
+			return err
+		}
+	}
+
+	// proposals created in an orphaned block lose their chain data until the ProposalCreated log is collected again
+	createdProposals, err := mongodb.DB.Collection("proposal_created_logs").Distinct(ctx, "proposal_id", fromFilter)
+	if err != nil {
+		return err
+	}
+	if _, err = mongodb.DB.Collection("proposal_created_logs").DeleteMany(ctx, fromFilter); err != nil {
+		return err
+	}
+	if len(createdProposals) > 0 {
+		_, err = mongodb.DB.Collection(NameProposals).UpdateMany(ctx, bson.D{
+			{Key: "proposal_id", Value: bson.D{{Key: "$in", Value: createdProposals}}},
+		}, bson.D{{Key: "$set", Value: bson.D{
+			{Key: "block_number", Value: 0},
+			{Key: "block_hash", Value: ""},
+			{Key: "state", Value: ProposalStatePending},
+		}}})
+		if err != nil {
+			return err
+		}
+	}
+
+	// canceled/executed proposals go back to their governor state
+	for _, final := range []struct{ coll, txHashKey, dateKey string }{
+		{"proposal_canceled_logs", "canceled_tx_hash", "canceled_at"},
+		{"proposal_executed_logs", "executed_tx_hash", "executed_at"},
+	} {
+		finishedProposals, err := mongodb.DB.Collection(final.coll).Distinct(ctx, "proposal_id", fromFilter)
+		if err != nil {
+			return err
+		}
+		if _, err = mongodb.DB.Collection(final.coll).DeleteMany(ctx, fromFilter); err != nil {
+			return err
+		}
+		for _, id := range finishedProposals {
+			if err = rollbackProposalState(ctx, fmt.Sprint(id), final.txHashKey, final.dateKey); err != nil {
+				return err
+			}
+		}
+	}
+
+	// delegation
+	for _, coll := range []string{NameDelegateVotesHistory, NameGovernanceParameterChanges} {
+		if _, err = mongodb.DB.Collection(coll).DeleteMany(ctx, fromFilter); err != nil {
+			return err
+		}
+	}
+	_, err = mongodb.DB.Collection(NameDelegations).UpdateMany(ctx, fromFilter, bson.A{
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "delegate", Value: "$previous_delegate"},
+			{Key: "block_number", Value: 0},
+			{Key: "log_index", Value: 0},
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// logs archived by the default handler
+	for _, cont := range []*Contract{GovCont, DaoCont} {
+		for _, evtName := range cont.ArchiveEvents() {
+			if HasHandler(evtName) {
+				continue
+			}
+			if _, err = (Target{}).logCollection(evtName).DeleteMany(ctx, fromFilter); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = mongodb.RemoveArchivedLogs(ctx, from); err != nil {
+		return err
+	}
+	if _, err = mongodb.DB.Collection(NameIndexedBlocks).DeleteMany(ctx, fromFilter); err != nil {
+		return err
+	}
+	if err = mongodb.RewindCheckpoints(ctx, from); err != nil {
+		return err
+	}
+
+	for _, id := range votedProposals {
+		if err = UpdateTotalSupply(ctx, fmt.Sprint(id)); err != nil {
+			log.Println(err)
+		}
+	}
+	util.Log(fmt.Sprintf("Rollback from block %d finished", from))
+	return nil
+}
+
+func rollbackProposalState(ctx context.Context, proposalId, txHashKey, dateKey string) error {
+	var proposal model.Proposal
+	err := mongodb.DB.Collection(NameProposals).FindOne(ctx, bson.D{
+		{Key: "proposal_id", Value: proposalId},
+	}).Decode(&proposal)
+	if err != nil {
+		if errors.Is(err, mongo2.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: txHashKey, Value: ""}, {Key: dateKey, Value: ""}}}}
+	state, err := ResolveProposalState(ctx, proposalId)
+	if err == nil {
+		update = append(update, bson.E{Key: "$set", Value: bson.D{{Key: "state", Value: state}}})
+	} else { // the stored state is kept, the log is usually collected again from the new canonical block
+		util.ErrorLog(err)
+	}
+	_, err = mongodb.DB.Collection(NameProposals).UpdateOne(ctx, bson.D{
+		{Key: "proposal_id", Value: proposalId},
+	}, update)
+	return err
+}
@@ -2,20 +2,48 @@ package event_logger
 
 import (
 	"boralabs/internal/chain"
+	"context"
+	"errors"
 	"log"
+	"slices"
 )
 
 type Collector struct {
 }
 
-func (c Collector) Collect() {
-	eventsMap := make(map[string]string)
-	eventsMap[chain.EventNameProposalCreated] = "proposals"
-	eventsMap[chain.EventNameVoteCast] = "vote_casts"
+// GovernorEvents Events of the governor collected by the Collector, proposals before the events referring to them.
+var GovernorEvents = []string{
+	chain.EventNameProposalCreated,
+	chain.EventNameVoteCast,
+	chain.EventNameProposalCanceled,
+	chain.EventNameProposalExecuted,
+	chain.EventNameVotingDelaySet,
+	chain.EventNameVotingPeriodSet,
+	chain.EventNameProposalThresholdSet,
+	chain.EventNameQuorumNumeratorUpdated,
+}
+
+func (c Collector) Collect(ctx context.Context) {
 	log.Println("Starting events collector")
 	defer log.Println("End events collector")
-	for evtName := range eventsMap {
-		evtLogger := NewLogger(chain.GovCont, evtName)
-		evtLogger.Collect(evtName)
+	logger := NewLogger(chain.GovCont, collectedEvents(chain.GovCont, GovernorEvents)...)
+	if err := chain.CheckReorg(ctx); err != nil {
+		log.Printf("Failed reorg check :: %v\n", err)
+		if !errors.Is(err, context.Canceled) {
+			logger.recordRun(ctx, err)
+		}
+		return
+	}
+	logger.Collect(ctx)
+}
+
+// collectedEvents The given events followed by the events of the contract archived by config.
+func collectedEvents(contract *chain.Contract, evtNames []string) []string {
+	events := slices.Clone(evtNames)
+	for _, evtName := range contract.ArchiveEvents() {
+		if !slices.Contains(events, evtName) {
+			events = append(events, evtName)
+		}
 	}
+	return events
 }
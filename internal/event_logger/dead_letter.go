@@ -0,0 +1,148 @@
+package event_logger
+
+import (
+	"boralabs/config"
+	"boralabs/internal/chain"
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/metrics"
+	"boralabs/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log"
+	"time"
+)
+
+const (
+	SourceCollector       = "collector"
+	SourceStreamer        = "streamer"
+	SourceProposalUpdater = "proposal_updater"
+	SourceVoteUpdater     = "vote_updater"
+	SourceRetry           = "retry"
+
+	defaultFailedLogMaxAttempts = 10
+	failedLogRetryTerm          = 30 * time.Second
+	failedLogMaxRetryTerm       = time.Hour
+	failedLogRetryBatch         = 100
+)
+
+// FailedLogRetrier Handle the logs of the failed_logs collection again with backoff.
+type FailedLogRetrier struct {
+}
+
+// Retry Retry the failed logs whose retry is due. A log that succeeds leaves the collection.
+func (r FailedLogRetrier) Retry(ctx context.Context) {
+	failedLogs, err := mongodb.DueFailedLogs(ctx, failedLogMaxAttempts(), failedLogRetryBatch)
+	if err != nil {
+		log.Printf("Failed find failed logs :: %v\n", err)
+		return
+	}
+	for _, failed := range failedLogs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err = RetryFailedLog(ctx, failed); err != nil {
+			log.Printf("Retry failed :: %s [%d] :: attempt %d :: %v\n", failed.TxHash, failed.LogIndex, failed.Attempts+1, err)
+		}
+	}
+}
+
+// RetryFailedLog Handle the failed log again, it is removed from failed_logs when it succeeds and rescheduled otherwise.
+func RetryFailedLog(ctx context.Context, failed model.FailedLog) error {
+	if err := HandleLog(ctx, failed.Log); err != nil {
+		deadLetter(ctx, SourceRetry, failed.Log, err)
+		return err
+	}
+	util.Log(fmt.Sprintf("Retried failed log %s [%d] after %d attempts", failed.TxHash, failed.LogIndex, failed.Attempts))
+	return mongodb.DeleteFailedLog(context.WithoutCancel(ctx), failed.ID)
+}
+
+// deadLetter Store the log that could not be decoded or saved in failed_logs, instead of skipping it for good.
+// A transient failure is retried with backoff, a permanent one waits for the admin API. Either is alerted once, when it is given up.
+func deadLetter(ctx context.Context, source string, eLog types.Log, cause error) {
+	if errors.Is(cause, context.Canceled) { // shut down before the log was saved, it is collected again
+		return
+	}
+	ctx = context.WithoutCancel(ctx) // the collector moves past the log, it is not lost on shutdown
+	log.Println(fmt.Sprintf(boraLabsErr.FailedSaveLogData, cause))
+	metrics.LogsFailed.WithLabelValues(source).Inc()
+	contract, evtName := logEvent(eLog)
+	failed, err := mongodb.RecordFailedLog(ctx, model.FailedLog{
+		Source:      source,
+		Contract:    contract,
+		Event:       evtName,
+		TxHash:      eLog.TxHash.Hex(),
+		LogIndex:    eLog.Index,
+		BlockNumber: eLog.BlockNumber,
+		Log:         eLog,
+		Error:       cause.Error(),
+		Permanent:   chain.IsPermanentErr(cause),
+	}, source == SourceRetry)
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf("Failed store failed log %s [%d] :: %v\n%v", eLog.TxHash.Hex(), eLog.Index, cause, err)))
+		return
+	}
+
+	if failed.Permanent || failed.Attempts >= failedLogMaxAttempts() {
+		alert, err := mongodb.MarkFailedLogAlerted(ctx, failed.ID)
+		if err != nil {
+			log.Printf("Failed mark failed log %s [%d] :: %v\n", failed.TxHash, failed.LogIndex, err)
+		}
+		if alert {
+			util.ErrorLog(errors.New(fmt.Sprintf("Giving up log %s [%d] after %d attempts, retry or discard it with the admin API :: %v", failed.TxHash, failed.LogIndex, failed.Attempts, cause)))
+		}
+		return
+	}
+	if err = mongodb.ScheduleFailedLog(ctx, failed.ID, time.Now().Add(retryTerm(failed.Attempts))); err != nil {
+		log.Printf("Failed schedule failed log %s [%d] :: %v\n", failed.TxHash, failed.LogIndex, err)
+	}
+}
+
+// failedLog Log that failed in a pass of an updater, dead-lettered once the updater gives up.
+type failedLog struct {
+	log   types.Log
+	cause error
+}
+
+// deadLetterAll Store the logs the updater gave up on in failed_logs.
+func deadLetterAll(ctx context.Context, source string, failed []failedLog) {
+	for _, f := range failed {
+		deadLetter(ctx, source, f.log, f.cause)
+	}
+}
+
+// retryTerm Backoff before the next retry, doubled after each attempt.
+func retryTerm(attempts int) time.Duration {
+	term := failedLogRetryTerm
+	for i := 1; i < attempts && term < failedLogMaxRetryTerm; i++ {
+		term = term * 2
+	}
+	return min(term, failedLogMaxRetryTerm)
+}
+
+// failedLogMaxAttempts Number of attempts after which a failed log is only retried from the admin API.
+func failedLogMaxAttempts() int {
+	maxAttempts := config.C.GetInt("failedLogMaxAttempts")
+	if maxAttempts <= 0 {
+		maxAttempts = defaultFailedLogMaxAttempts
+	}
+	return maxAttempts
+}
+
+// logEvent Contract address and event name of the log, empty when the log does not belong to a known event.
+func logEvent(eLog types.Log) (contract, evtName string) {
+	if len(eLog.Topics) == 0 {
+		return
+	}
+	cont, err := chain.ContractByAddress(eLog.Address)
+	if err != nil {
+		return
+	}
+	if evtName, err = cont.EventName(eLog.Topics[0]); err != nil {
+		return
+	}
+	return cont.Address(), evtName
+}
@@ -0,0 +1,49 @@
+package event_logger
+
+import (
+	"boralabs/config"
+	"testing"
+	"time"
+)
+
+func TestRetryTerm(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryTerm(tt.attempts); got != tt.want {
+			t.Errorf("retryTerm(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestFailedLogMaxAttempts(t *testing.T) {
+	defer config.C.Set("failedLogMaxAttempts", 0)
+	tests := []struct {
+		name       string
+		configured int
+		want       int
+	}{
+		{"default when unset", 0, defaultFailedLogMaxAttempts},
+		{"default when negative", -1, defaultFailedLogMaxAttempts},
+		{"configured", 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.C.Set("failedLogMaxAttempts", tt.configured)
+			if got := failedLogMaxAttempts(); got != tt.want {
+				t.Errorf("failedLogMaxAttempts() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package event_logger
+
+import (
+	"boralabs/internal/chain"
+	"context"
+	"log"
+)
+
+type DelegationCollector struct {
+}
+
+// TokenEvents Events of the DAO token collected by the DelegationCollector.
+var TokenEvents = []string{
+	chain.EventNameDelegateChanged,
+	chain.EventNameDelegateVotesChanged,
+}
+
+// Collect DelegateChanged and DelegateVotesChanged logs of the DAO token.
+func (c DelegationCollector) Collect(ctx context.Context) {
+	log.Println("Starting delegation collector")
+	defer log.Println("End delegation collector")
+	NewLogger(chain.DaoCont, collectedEvents(chain.DaoCont, TokenEvents)...).Collect(ctx)
+}
@@ -0,0 +1,127 @@
+package event_logger
+
+import (
+	"boralabs/config"
+	"boralabs/pkg/datastore/mongodb"
+	"boralabs/pkg/util"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	LeaseIndexer          = "indexer"
+	defaultLeaderLeaseTTL = 30 * time.Second
+)
+
+// Leader Lease based leader election between the replicas, only the leader runs the collection jobs.
+// The leader renews its lease with a heartbeat, another instance takes it over once it expired.
+// The jobs run with the context of the term, cancelled as soon as a heartbeat fails or the lease is lost.
+type Leader struct {
+	name       string
+	holder     string
+	ttl        time.Duration
+	isLeader   atomic.Bool
+	mu         sync.Mutex
+	term       context.Context
+	cancelTerm context.CancelFunc
+}
+
+// NewLeader Candidate for the lease, identified by the instanceId config or the host name and pid.
+func NewLeader(name string) *Leader {
+	ttl := config.C.GetDuration("leaderLeaseTTL")
+	if ttl <= 0 {
+		ttl = defaultLeaderLeaseTTL
+	}
+	return &Leader{name: name, holder: instanceID(), ttl: ttl}
+}
+
+// Start Try to take the lease, then take or renew it every third of its ttl in the background until the context is done.
+func (l *Leader) Start(ctx context.Context) {
+	l.heartbeat(ctx)
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				l.heartbeat(ctx)
+			}
+		}
+	}()
+}
+
+// IsLeader Whether the instance holds the lease.
+func (l *Leader) IsLeader() bool {
+	return l.isLeader.Load()
+}
+
+// Term Context of the current term, done when the lease is lost or released. ok is false when the instance is not the leader.
+func (l *Leader) Term() (term context.Context, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.isLeader.Load() {
+		return nil, false
+	}
+	return l.term, true
+}
+
+// Release Give up the lease, e.g. on shutdown. The lease is released even when the context is done.
+func (l *Leader) Release(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.isLeader.Swap(false) {
+		return
+	}
+	l.cancelTerm()
+	if err := mongodb.ReleaseLease(context.WithoutCancel(ctx), l.name, l.holder); err != nil {
+		log.Printf("Failed release lease %s :: %v\n", l.name, err)
+	}
+}
+
+func (l *Leader) heartbeat(ctx context.Context) {
+	acquired, err := mongodb.AcquireLease(ctx, l.name, l.holder, l.ttl)
+	if err != nil {
+		// without a renewal the lease is not safe anymore once the ttl passed
+		log.Printf("Failed renew lease %s :: %v\n", l.name, err)
+		acquired = false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if was := l.isLeader.Swap(acquired); was != acquired {
+		if acquired {
+			l.term, l.cancelTerm = context.WithCancel(ctx)
+			util.Log(fmt.Sprintf("Leader of %s :: %s", l.name, l.holder))
+		} else {
+			l.cancelTerm() // stop the jobs of the term, another instance may take the lease over
+			util.ErrorLog(errors.New(fmt.Sprintf("Lost the lease of %s :: %s", l.name, l.holder)))
+		}
+	}
+}
+
+// leaderTerm Context to run a job with: the term of the leader, or ctx without a leader. ok is false when not the leader.
+func leaderTerm(ctx context.Context, l *Leader) (context.Context, bool) {
+	if l == nil {
+		return ctx, true
+	}
+	return l.Term()
+}
+
+func instanceID() string {
+	if id := config.C.GetString("instanceId"); id != "" {
+		return id
+	}
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
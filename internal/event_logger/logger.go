@@ -1,64 +1,204 @@
 package event_logger
 
 import (
+	"boralabs/config"
 	"boralabs/internal/chain"
 	"boralabs/pkg/datastore/mongodb"
-	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/metrics"
 	"boralabs/pkg/util"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/core/types"
 	"log"
+	"math/big"
+	"time"
 )
 
 type Logger struct {
 	*chain.Contract
+	events []string
+	filter func(ctx context.Context, from, to uint64) ([]types.Log, error) // filterLogs, replaced in the tests
 }
 
-const retryLimit = 3
+const (
+	retryLimit             = 3
+	defaultFilterChunkSize = 2000
+	rateLimitRetryTerm     = 2 * time.Second
+)
 
-func NewLogger(contract *chain.Contract, evtName string) *Logger {
-	var err error
-	evt := chain.Event{Cont: contract}
-	if evt, err = evt.New(evtName); err != nil {
-		panic(err)
+// NewLogger Logger collecting the events of the contract with one eth_getLogs call per block range.
+func NewLogger(contract *chain.Contract, evtNames ...string) *Logger {
+	for _, evtName := range evtNames {
+		evt := chain.Event{Cont: contract}
+		if _, err := evt.New(evtName); err != nil {
+			panic(err)
+		}
 	}
 
-	return &Logger{Contract: contract}
+	l := &Logger{Contract: contract, events: evtNames}
+	l.filter = l.filterLogs
+	return l
+}
+
+// Collect Collect the confirmed blocks after the checkpoints and record the outcome of the run.
+func (l *Logger) Collect(ctx context.Context) {
+	defer l.reportLag(ctx)
+	err := l.collect(ctx)
+	if errors.Is(err, context.Canceled) {
+		log.Printf("Stopped events collector :: %s %v\n", l.Name(), l.events)
+		return
+	}
+	if err != nil {
+		log.Println(err) // keep the checkpoints, the rest of the range is collected again on the next run
+	}
+	l.recordRun(ctx, err)
 }
 
-func (l *Logger) Collect(evtName string) {
-	var err error
-	evt := chain.Event{Cont: l.Contract}
-	if evt, err = evt.New(evtName); err != nil {
-		panic(err)
+func (l *Logger) collect(ctx context.Context) error {
+	startBlock, err := l.startBlock(ctx)
+	if err != nil {
+		return err
+	}
+	endBlock, err := l.SafeBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if startBlock > endBlock {
+		log.Printf("no confirmed blocks to collect :: %s :: [%d > %d]\n", l.Name(), startBlock, endBlock)
+		return nil
 	}
 
-	var logs []types.Log
-	startBlock := mongodb.CalcFromBlock(collectionName)
-	tryCnt := 1
-	log.Printf("[%s] Starting events collector :: %s :: [Start BlockNumber - %d]\n", util.NowInKst().String(), evtName, startBlock)
-	defer log.Printf("[%s] End events collector :: %s :: [Start BlockNumber - %d]\n", util.NowInKst().String(), evtName, startBlock)
-	for tryCnt <= retryLimit {
-		logs, err = chain.GovCont.FilterLogs(evt.Signature, startBlock)
+	log.Printf("[%s] Starting events collector :: %s %v :: [Start BlockNumber - %d / End BlockNumber - %d]\n", util.NowInKst().String(), l.Name(), l.events, startBlock, endBlock)
+	defer log.Printf("[%s] End events collector :: %s %v :: [Start BlockNumber - %d / End BlockNumber - %d]\n", util.NowInKst().String(), l.Name(), l.events, startBlock, endBlock)
+	defer l.recordBlock(ctx, endBlock)
+
+	// the checkpoints are saved after each chunk
+	return l.collectRange(ctx, startBlock, endBlock, func(to uint64) {
+		for _, evtName := range l.events {
+			l.saveCheckpoint(ctx, evtName, to)
+		}
+	})
+}
+
+// collectRange Walk the range in chunks and handle the logs of each one, calling done after each chunk.
+// The failed logs go to the dead-letter queue, only a failed eth_getLogs call stops the walk.
+// When the context is done the current log is finished and the walk stops without completing the chunk.
+func (l *Logger) collectRange(ctx context.Context, startBlock, endBlock uint64, done func(to uint64)) error {
+	chunkSize := filterChunkSize()
+	for from := startBlock; from <= endBlock; {
+		to := min(from+chunkSize-1, endBlock)
+		logs, err := l.filter(ctx, from, to)
 		if err != nil {
-			log.Println(err)
+			if chain.IsRangeTooLargeErr(err) && to > from { // halved from the rejected range, it may be shorter than the chunk
+				chunkSize = (to - from + 1) / 2
+				log.Printf("Block range too large :: %s :: [%d - %d] :: retry with chunk size %d\n", l.Name(), from, to, chunkSize)
+				continue
+			}
+			return err
+		}
+
+		// the logs are ordered by block and log index, each one is dispatched by its signature hash
+		for _, eLog := range logs {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			if err = HandleLog(ctx, eLog); err != nil {
+				deadLetter(ctx, SourceCollector, eLog, err)
+			}
 		}
-		tryCnt++
+		done(to)
+		log.Printf("Collected :: %s :: [%d - %d] :: %d logs\n", l.Name(), from, to, len(logs))
+		from = to + 1
 	}
+	return nil
+}
 
-	if len(logs) == 0 {
-		log.Printf("not found logs :: %s\n", evtName)
-		return
+// startBlock The lowest block any of the events resumes from. Events already collected further are saved again, which is idempotent.
+func (l *Logger) startBlock(ctx context.Context) (uint64, error) {
+	var startBlock uint64
+	for i, evtName := range l.events {
+		block, err := mongodb.StartBlock(ctx, l.Address(), evtName, l.FromBlock())
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 || block < startBlock {
+			startBlock = block
+		}
 	}
+	return startBlock, nil
+}
 
-	for _, eLog := range logs {
-		if err = chain.GovCont.UnpackLogData(evt.Out, evt.Name, eLog); err != nil {
-			log.Println(fmt.Sprintf(boraLabsErr.FailedParseLogData, err))
+// filterLogs FilterLogs with retries. A range too large error is returned right away so the caller can shrink the range.
+func (l *Logger) filterLogs(ctx context.Context, from, to uint64) (logs []types.Log, err error) {
+	for tryCnt := 1; tryCnt <= retryLimit; tryCnt++ {
+		logs, err = l.FilterLogs(ctx, from, to, l.events...)
+		if err == nil || chain.IsRangeTooLargeErr(err) || ctx.Err() != nil {
+			return
 		}
-		// log save
-		err = evt.SaveLog("", eLog)
+		log.Println(err)
+		if chain.IsRateLimitErr(err) && tryCnt < retryLimit {
+			sleep(ctx, time.Duration(tryCnt)*rateLimitRetryTerm)
+		}
+	}
+	return
+}
+
+// filterChunkSize Number of blocks queried by one eth_getLogs call.
+func filterChunkSize() uint64 {
+	chunkSize := config.C.GetUint64("filterChunkSize")
+	if chunkSize == 0 {
+		chunkSize = defaultFilterChunkSize
+	}
+	return chunkSize
+}
+
+// reportLag Export the number of blocks between the chain head and the checkpoint of each event.
+func (l *Logger) reportLag(ctx context.Context) {
+	head, err := l.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	for _, evtName := range l.events {
+		startBlock, err := mongodb.StartBlock(ctx, l.Address(), evtName, l.FromBlock())
 		if err != nil {
-			log.Println(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err))
+			continue
+		}
+		var lag uint64
+		if head+1 > startBlock {
+			lag = head + 1 - startBlock
+		}
+		metrics.IndexerLag.WithLabelValues(l.Name(), evtName).Set(float64(lag))
+	}
+}
+
+// recordRun Store the outcome of the run of each event for the status API.
+func (l *Logger) recordRun(ctx context.Context, runErr error) {
+	for _, evtName := range l.events {
+		if err := mongodb.RecordIndexerRun(ctx, l.Address(), evtName, runErr); err != nil {
+			log.Printf("Failed record indexer run :: %s :: %v\n", evtName, err)
 		}
 	}
 }
+
+// saveCheckpoint Store the checkpoint of a chunk whose logs were all handled, even on shutdown.
+func (l *Logger) saveCheckpoint(ctx context.Context, evtName string, blockNumber uint64) {
+	if err := mongodb.SaveCheckpoint(context.WithoutCancel(ctx), l.Address(), evtName, blockNumber); err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf("Failed save checkpoint :: %s :: %v", evtName, err)))
+	}
+}
+
+// recordBlock Record the hash of the last scanned block, a reorg below it changes the hash.
+func (l *Logger) recordBlock(ctx context.Context, blockNumber uint64) {
+	if ctx.Err() != nil { // shutting down
+		return
+	}
+	header, err := l.HeaderByNumber(ctx, big.NewInt(int64(blockNumber)))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err = chain.RecordBlock(ctx, blockNumber, header.Hash().Hex()); err != nil {
+		log.Println(err)
+	}
+}
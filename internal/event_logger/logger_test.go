@@ -0,0 +1,86 @@
+package event_logger
+
+import (
+	"boralabs/internal/chain"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/core/types"
+	"reflect"
+	"testing"
+)
+
+func TestCollectRangeChunks(t *testing.T) {
+	tooLarge := errors.New("query returned more than 10000 results")
+	type blockRange struct{ from, to uint64 }
+	tests := []struct {
+		name       string
+		start, end uint64
+		maxRange   uint64 // the node rejects larger ranges, 0 for no limit
+		failAt     uint64 // the node fails the range starting at this block, 0 for never
+		tooLargeAt uint64 // the node rejects the range starting at this block as too large whatever its size, 0 for never
+		wantCalls  []blockRange
+		wantDone   []uint64
+		wantErr    bool
+	}{
+		{
+			name: "single chunk", start: 1, end: 1500,
+			wantCalls: []blockRange{{1, 1500}},
+			wantDone:  []uint64{1500},
+		},
+		{
+			name: "chunks of the configured size", start: 1, end: 4500,
+			wantCalls: []blockRange{{1, 2000}, {2001, 4000}, {4001, 4500}},
+			wantDone:  []uint64{2000, 4000, 4500},
+		},
+		{
+			name: "halved until the node accepts the range", start: 1, end: 1200, maxRange: 500,
+			wantCalls: []blockRange{{1, 1200}, {1, 600}, {1, 300}, {301, 600}, {601, 900}, {901, 1200}},
+			wantDone:  []uint64{300, 600, 900, 1200},
+		},
+		{
+			name: "down to a single block", start: 10, end: 11, maxRange: 1,
+			wantCalls: []blockRange{{10, 11}, {10, 10}, {11, 11}},
+			wantDone:  []uint64{10, 11},
+		},
+		{
+			name: "a single block too large is an error", start: 10, end: 11, maxRange: 1, tooLargeAt: 11,
+			wantCalls: []blockRange{{10, 11}, {10, 10}, {11, 11}},
+			wantDone:  []uint64{10},
+			wantErr:   true,
+		},
+		{
+			name: "other errors stop the walk", start: 1, end: 4500, failAt: 2001,
+			wantCalls: []blockRange{{1, 2000}, {2001, 4000}},
+			wantDone:  []uint64{2000},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []blockRange
+			var done []uint64
+			l := &Logger{Contract: &chain.Contract{}}
+			l.filter = func(ctx context.Context, from, to uint64) ([]types.Log, error) {
+				calls = append(calls, blockRange{from, to})
+				if (tt.maxRange > 0 && to-from+1 > tt.maxRange) || (tt.tooLargeAt > 0 && from == tt.tooLargeAt) {
+					return nil, tooLarge
+				}
+				if tt.failAt > 0 && from == tt.failAt {
+					return nil, errors.New("connection refused")
+				}
+				return nil, nil
+			}
+
+			err := l.collectRange(context.Background(), tt.start, tt.end, func(to uint64) { done = append(done, to) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("collectRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("eth_getLogs ranges = %v, want %v", calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(done, tt.wantDone) {
+				t.Errorf("done = %v, want %v", done, tt.wantDone)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package event_logger
+
+import (
+	"boralabs/config"
+	"boralabs/internal/chain"
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	"boralabs/pkg/notification"
+	"boralabs/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"time"
+)
+
+const (
+	defaultProposalRefreshInterval = time.Minute
+	proposalTransitionRetryTerm    = 5 * time.Second
+)
+
+// proposalSchedulerWake Wake the scheduler up when a proposal was saved, so its dates are scheduled right away.
+var proposalSchedulerWake = make(chan struct{}, 1)
+
+// ProposalScheduler Move each open proposal to its next state when its start_date or end_date passes.
+// At the boundary the state is read from the governor, a proposal the chain still reports in the previous state,
+// because no block past the boundary was mined yet, is tried again every few seconds.
+// The proposals past their end date that are not final yet are also refreshed every proposalRefreshInterval
+// for the transitions the dates do not tell, e.g. succeeded to queued. With a Leader only the leader schedules.
+type ProposalScheduler struct {
+	Leader *Leader
+}
+
+// Run Wait for the next boundary and move the due proposals, until the context is done.
+func (s ProposalScheduler) Run(ctx context.Context) {
+	var refreshAt time.Time // refreshed right away
+	for ctx.Err() == nil {
+		next := time.Now().Add(streamRetryTerm)
+		if term, ok := leaderTerm(ctx, s.Leader); ok {
+			refresh := !time.Now().Before(refreshAt)
+			if refresh {
+				refreshAt = time.Now().Add(proposalRefreshInterval())
+			}
+			next = s.transition(term, refresh)
+			if next.IsZero() || refreshAt.Before(next) {
+				next = refreshAt
+			}
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		case <-proposalSchedulerWake:
+		}
+		timer.Stop()
+	}
+}
+
+// transition Move the proposals whose boundary passed, and the ones past all their boundaries when refresh is set.
+// Return the time of the next boundary, zero when no proposal is open.
+func (s ProposalScheduler) transition(ctx context.Context, refresh bool) (next time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			util.ErrorLog(errors.New(fmt.Sprintf("Panic Proposal Scheduler %v", r)))
+			util.PrintStackTrace()
+			next = time.Now().Add(proposalTransitionRetryTerm)
+		}
+	}()
+
+	// proposals submitted through the API have no dates until their ProposalCreated log is saved
+	cursor, err := mongodb.DB.Collection(collectionName).Find(ctx, bson.D{
+		{Key: "block_number", Value: bson.D{{Key: "$gt", Value: 0}}},
+		{Key: "state", Value: bson.D{{Key: "$nin", Value: bson.A{
+			chain.ProposalStateCanceled,
+			chain.ProposalStateExecuted,
+			chain.ProposalStateDefeated,
+			chain.ProposalStateExpired,
+		}}}},
+	})
+	if err != nil {
+		log.Printf("Failed find open proposals :: %v\n", err)
+		return time.Now().Add(proposalTransitionRetryTerm)
+	}
+	var proposals []model.Proposal
+	if err = cursor.All(ctx, &proposals); err != nil {
+		log.Printf("Failed find open proposals :: %v\n", err)
+		return time.Now().Add(proposalTransitionRetryTerm)
+	}
+
+	for _, proposal := range proposals {
+		if ctx.Err() != nil {
+			return
+		}
+		state := proposal.State
+		if boundary := nextBoundary(state, proposal); (refresh && boundary.IsZero()) || (!boundary.IsZero() && !boundary.After(time.Now())) {
+			state = s.update(ctx, proposal)
+		}
+		boundary := nextBoundary(state, proposal)
+		if boundary.IsZero() {
+			continue
+		}
+		if !boundary.After(time.Now()) { // the chain did not pass the boundary yet
+			boundary = time.Now().Add(proposalTransitionRetryTerm)
+		}
+		if next.IsZero() || boundary.Before(next) {
+			next = boundary
+		}
+	}
+	return
+}
+
+// update Store the current state of the proposal. Leaving pending or active the totals are recomputed,
+// and closing the vote is notified.
+func (s ProposalScheduler) update(ctx context.Context, proposal model.Proposal) string {
+	state, err := chain.ResolveProposalState(ctx, proposal.ProposalID)
+	if err != nil { // the stored state is kept and read again after proposalTransitionRetryTerm
+		log.Println(err)
+		return proposal.State
+	}
+	if state == proposal.State || ctx.Err() != nil {
+		return proposal.State
+	}
+
+	// only from the state read, a log saved in the meantime wins
+	ctx = context.WithoutCancel(ctx)
+	res, err := mongodb.DB.Collection(collectionName).UpdateOne(ctx, bson.D{
+		{Key: "proposal_id", Value: proposal.ProposalID},
+		{Key: "state", Value: proposal.State},
+	}, bson.D{{Key: "$set", Value: bson.D{{Key: "state", Value: state}}}})
+	if err != nil {
+		util.ErrorLog(errors.New(fmt.Sprintf("Failed update proposal state [%s] :: %v", proposal.ProposalID, err)))
+		return proposal.State
+	}
+	if res.ModifiedCount == 0 {
+		return proposal.State
+	}
+	util.Log(fmt.Sprintf("Proposal state [%s] [%s -> %s]", proposal.ProposalID, proposal.State, state))
+
+	if !isOpenProposalState(proposal.State) {
+		return state
+	}
+	if err = chain.UpdateTotalSupply(ctx, proposal.ProposalID); err != nil {
+		util.Log(fmt.Sprintf("Failed update total supply :: [Proposal ID:%s]", proposal.ProposalID))
+	}
+	if !isOpenProposalState(state) {
+		notifyProposalClosed(ctx, proposal.ProposalID, state)
+	}
+	return state
+}
+
+// notifyProposalClosed Send the result of the vote of the proposal.
+func notifyProposalClosed(ctx context.Context, proposalID, state string) {
+	var proposal model.Proposal
+	if err := mongodb.DB.Collection(collectionName).FindOne(ctx, bson.D{
+		{Key: "proposal_id", Value: proposalID},
+	}).Decode(&proposal); err != nil {
+		log.Printf("Failed find proposal [%s] :: %v\n", proposalID, err)
+		return
+	}
+	notification.SendAll(fmt.Sprintf("Proposal [%d] %s closed [%s] :: for %s / against %s / abstain %s :: quorum %s reached %v :: voting ratio %s%%",
+		proposal.ID, proposal.Title, state, proposal.ForVotes, proposal.AgainstVotes, proposal.AbstainVotes, proposal.Quorum, proposal.QuorumReached, proposal.VotingRatio))
+}
+
+// nextBoundary Date the proposal in the given state moves to its next state, zero when no date moves it anymore.
+func nextBoundary(state string, proposal model.Proposal) time.Time {
+	switch state {
+	case chain.ProposalStatePending, "":
+		return proposal.StartDate
+	case chain.ProposalStateActive:
+		return proposal.EndDate
+	}
+	return time.Time{}
+}
+
+// isOpenProposalState Whether the vote of the proposal did not end yet.
+func isOpenProposalState(state string) bool {
+	return state == chain.ProposalStatePending || state == chain.ProposalStateActive || state == ""
+}
+
+// wakeProposalScheduler Let the scheduler pick up a proposal saved outside of it.
+func wakeProposalScheduler() {
+	select {
+	case proposalSchedulerWake <- struct{}{}:
+	default:
+	}
+}
+
+func proposalRefreshInterval() time.Duration {
+	interval := config.C.GetDuration("proposalRefreshInterval")
+	if interval <= 0 {
+		interval = defaultProposalRefreshInterval
+	}
+	return interval
+}
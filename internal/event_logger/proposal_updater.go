@@ -6,10 +6,11 @@ import (
 	"boralabs/pkg/datastore/mongodb"
 	boraLabsErr "boralabs/pkg/error"
 	"boralabs/pkg/util"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/core/types"
-	"log"
+	"go.mongodb.org/mongo-driver/bson"
 	"time"
 )
 
@@ -25,11 +26,15 @@ func (p *ProposalUpdater) Default() {
 	p.State = chain.ProposalStatePending
 }
 
-func (p *ProposalUpdater) Update(startBlock uint64) (ret bool) {
+// Update Attach the chain data of the ProposalCreated log to the proposal.
+// attached is false without an error when the log is not confirmed within the retries,
+// the collector attaches the proposal once its block is confirmations deep.
+func (p *ProposalUpdater) Update(ctx context.Context) (attached bool, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			util.ErrorLog(errors.New(fmt.Sprintf("Panic Proposal AppendSave %v", r)))
 			util.PrintStackTrace()
+			attached, err = false, errors.New(fmt.Sprintf(boraLabsErr.FailedUpdateLogData, r))
 		}
 	}()
 
@@ -40,20 +45,30 @@ func (p *ProposalUpdater) Update(startBlock uint64) (ret bool) {
 	}
 
 	var logs []types.Log
-	from := mongodb.CalcFromBlock(collectionName)
-	if startBlock != 0 {
-		from = startBlock
+	from, err := p.fromBlock(ctx)
+	if err != nil {
+		panic(err)
 	}
 	defaultTerm := 1 * time.Second
 	tryCnt := 1
 	var data *model.ProposalCreatedLog
 	var ok bool
 	matchCnt := 0
+	var failed []failedLog // of the last pass, every pass handles all the logs again
+	defer func() { deadLetterAll(ctx, SourceProposalUpdater, failed) }()
 	for tryCnt <= retryLimit {
-		logs, err = chain.GovCont.FilterLogs(evt.Signature, from)
+		// only confirmed blocks, like the collector, the log of a proposal just submitted shows up once its block is safe
+		to, err := chain.GovCont.SafeBlockNumber(ctx)
 		if err != nil {
 			panic(err)
 		}
+		logs, failed = nil, nil
+		if from <= to {
+			logs, err = chain.GovCont.FilterLogs(ctx, from, to, evt.Name)
+			if err != nil {
+				panic(err)
+			}
+		}
 
 		for _, eLog := range logs {
 			if data, ok = evt.Out.(*model.ProposalCreatedLog); !ok {
@@ -61,12 +76,13 @@ func (p *ProposalUpdater) Update(startBlock uint64) (ret bool) {
 				continue
 			}
 
-			if err = chain.GovCont.UnpackLogData(evt.Out, evt.Name, eLog); err != nil {
-				log.Println(fmt.Sprintf(boraLabsErr.FailedParseLogData, err))
+			if err = evt.Decode(eLog); err != nil {
+				failed = append(failed, failedLog{eLog, chain.Permanent(errors.New(fmt.Sprintf(boraLabsErr.FailedParseLogData, err)))})
+				continue
 			}
-			err = evt.SaveLog(data.ProposalId.String(), eLog)
+			err = evt.SaveLog(ctx, data.ProposalId.String(), eLog)
 			if err != nil {
-				log.Println(err)
+				failed = append(failed, failedLog{eLog, err})
 				continue
 			}
 
@@ -76,13 +92,28 @@ func (p *ProposalUpdater) Update(startBlock uint64) (ret bool) {
 		}
 
 		if matchCnt > 0 {
-			ret = true
+			wakeProposalScheduler()
+			attached = true
 			break
 		}
 
-		time.Sleep(defaultTerm)
+		sleep(ctx, defaultTerm)
 		defaultTerm = defaultTerm + defaultTerm // 1 - 2 - 4
 		tryCnt++
 	}
 	return
 }
+
+// fromBlock Block of the ProposalCreated log when the collector already stored it, otherwise the block after the collector checkpoint.
+func (p *ProposalUpdater) fromBlock(ctx context.Context) (uint64, error) {
+	var createdLog struct {
+		BlockNumber uint64 `bson:"block_number"`
+	}
+	err := mongodb.DB.Collection("proposal_created_logs").FindOne(ctx, bson.D{
+		{Key: "proposal_id", Value: p.ProposalID},
+	}).Decode(&createdLog)
+	if err == nil && createdLog.BlockNumber > 0 {
+		return createdLog.BlockNumber, nil
+	}
+	return mongodb.StartBlock(ctx, chain.GovCont.Address(), chain.EventNameProposalCreated, chain.GovCont.FromBlock())
+}
@@ -0,0 +1,61 @@
+package event_logger
+
+import (
+	"boralabs/internal/chain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+)
+
+// Reindex Collect the events again over the block range with the collector handlers, leaving the checkpoints as they are.
+// Without events all collected governor and token events are reindexed, a zero to block stands for the last confirmed block.
+func Reindex(ctx context.Context, from, to uint64, evtNames []string) error {
+	if to == 0 {
+		head, err := chain.GovCont.SafeBlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		to = head
+	}
+	if from > to {
+		return errors.New(fmt.Sprintf("Invalid block range [%d - %d]", from, to))
+	}
+
+	for _, evtName := range evtNames {
+		if _, err := chain.ContractByEvent(evtName); err != nil {
+			return err
+		}
+	}
+	for _, group := range []struct {
+		contract *chain.Contract
+		events   []string
+	}{
+		{chain.GovCont, collectedEvents(chain.GovCont, GovernorEvents)},
+		{chain.DaoCont, collectedEvents(chain.DaoCont, TokenEvents)},
+	} {
+		events := group.events
+		if len(evtNames) > 0 {
+			events = nil
+			for _, evtName := range evtNames {
+				if cont, _ := chain.ContractByEvent(evtName); cont == group.contract && !slices.Contains(events, evtName) {
+					events = append(events, evtName)
+				}
+			}
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		log.Printf("Reindex :: %s %v :: [%d - %d]\n", group.contract.Name(), events, from, to)
+		total := to - from + 1
+		err := NewLogger(group.contract, events...).collectRange(ctx, from, to, func(done uint64) {
+			log.Printf("Reindex progress :: %s :: %d / %d blocks (%.1f%%)\n", group.contract.Name(), done-from+1, total, float64(done-from+1)*100/float64(total))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
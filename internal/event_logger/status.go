@@ -0,0 +1,117 @@
+package event_logger
+
+import (
+	"boralabs/internal/chain"
+	"boralabs/internal/model"
+	"boralabs/pkg/datastore/mongodb"
+	"context"
+	"math/big"
+)
+
+type indexedContract struct {
+	contract *chain.Contract
+	events   []string
+}
+
+// indexedContracts Contracts of the collectors with the events collected from each.
+func indexedContracts() []indexedContract {
+	return []indexedContract{
+		{chain.GovCont, collectedEvents(chain.GovCont, GovernorEvents)},
+		{chain.DaoCont, collectedEvents(chain.DaoCont, TokenEvents)},
+	}
+}
+
+// Status Progress of the indexer for each collected event, against the current chain head.
+func Status(ctx context.Context) ([]model.IndexerStatus, error) {
+	head, err := chain.GovCont.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	blockTimes := map[uint64]uint64{head.Number.Uint64(): head.Time}
+	blockTime := func(blockNumber uint64) (uint64, error) {
+		if t, ok := blockTimes[blockNumber]; ok {
+			return t, nil
+		}
+		header, err := chain.GovCont.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+		if err != nil {
+			return 0, err
+		}
+		blockTimes[blockNumber] = header.Time
+		return header.Time, nil
+	}
+
+	statuses := make([]model.IndexerStatus, 0)
+	for _, c := range indexedContracts() {
+		for _, evtName := range c.events {
+			status, err := eventStatus(ctx, c.contract, evtName, head.Number.Uint64(), head.Time, blockTime)
+			if err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+func eventStatus(ctx context.Context, contract *chain.Contract, evtName string, head, headTime uint64, blockTime func(uint64) (uint64, error)) (model.IndexerStatus, error) {
+	status := model.IndexerStatus{
+		Contract:  contract.Name(),
+		Address:   contract.Address(),
+		Event:     evtName,
+		ChainHead: head,
+	}
+
+	// without a checkpoint nothing was processed yet, the lag counts from fromBlock of the contract
+	checkpoint, found, err := mongodb.GetCheckpoint(ctx, contract.Address(), evtName)
+	if err != nil {
+		return status, err
+	}
+	startBlock := contract.FromBlock()
+	if found {
+		startBlock = checkpoint.BlockNumber + 1
+	}
+	if head+1 > startBlock {
+		status.LagBlocks = head + 1 - startBlock
+	}
+	if found {
+		lastProcessed := checkpoint.BlockNumber
+		status.LastProcessedBlock = &lastProcessed
+		if lastProcessed > 0 && lastProcessed <= head {
+			t, err := blockTime(lastProcessed)
+			if err != nil {
+				return status, err
+			}
+			status.LagSeconds = headTime - min(t, headTime)
+		}
+	}
+
+	run, found, err := mongodb.GetIndexerRun(ctx, contract.Address(), evtName)
+	if err != nil {
+		return status, err
+	}
+	if found {
+		status.LastSuccessAt = run.LastSuccessAt
+		status.LastError, status.LastErrorAt = run.LastError, run.LastErrorAt
+	}
+
+	if status.FailedLogs, err = mongodb.CountFailedLogs(ctx, contract.Address(), evtName); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+// MaxLag Largest number of blocks between the given block and the checkpoint of a collected event, with that event.
+func MaxLag(ctx context.Context, blockNumber uint64) (lag uint64, evtName string, err error) {
+	for _, c := range indexedContracts() {
+		for _, name := range c.events {
+			startBlock, err := mongodb.StartBlock(ctx, c.contract.Address(), name, c.contract.FromBlock())
+			if err != nil {
+				return 0, "", err
+			}
+			if blockNumber+1 > startBlock && blockNumber+1-startBlock > lag {
+				lag, evtName = blockNumber+1-startBlock, name
+			}
+		}
+	}
+	return lag, evtName, nil
+}
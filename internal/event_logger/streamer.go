@@ -0,0 +1,130 @@
+package event_logger
+
+import (
+	"boralabs/internal/chain"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/metrics"
+	"boralabs/pkg/util"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/core/types"
+	"log"
+	"time"
+)
+
+const (
+	streamRetryTerm    = 5 * time.Second
+	streamMaxRetryTerm = 5 * time.Minute
+)
+
+// Streamer Save the governor and token logs as soon as they are emitted, over a websocket subscription.
+// The checkpointed polling collectors keep running next to it, they collect whatever the stream missed
+// while it was down, so a subscription error only delays new logs until the next polling run.
+// With a Leader only the leader streams.
+type Streamer struct {
+	Leader *Leader
+}
+
+// Stream Subscribe and save logs, resubscribing with backoff when the subscription fails, until the context is done.
+func (s Streamer) Stream(ctx context.Context) {
+	retryTerm := streamRetryTerm
+	for ctx.Err() == nil {
+		term, ok := leaderTerm(ctx, s.Leader)
+		if !ok {
+			sleep(ctx, streamRetryTerm)
+			continue
+		}
+
+		startedAt := time.Now()
+		err := s.subscribe(term)
+		if err == nil || term.Err() != nil { // the leadership was lost or shutting down
+			continue
+		}
+		if time.Since(startedAt) > streamMaxRetryTerm { // the subscription was healthy for a while
+			retryTerm = streamRetryTerm
+		}
+		util.ErrorLog(errors.New(fmt.Sprintf("Log subscription stopped, falling back to polling for %s :: %v", retryTerm, err)))
+
+		sleep(ctx, retryTerm)
+		retryTerm = min(retryTerm*2, streamMaxRetryTerm)
+	}
+}
+
+func (s Streamer) subscribe(ctx context.Context) error {
+	wsEcl, err := chain.DialStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer wsEcl.Close()
+
+	logsCh := make(chan types.Log, 128)
+	govSub, err := chain.GovCont.SubscribeFilterLogs(ctx, wsEcl, collectedEvents(chain.GovCont, GovernorEvents), logsCh)
+	if err != nil {
+		return err
+	}
+	defer govSub.Unsubscribe()
+	daoSub, err := chain.DaoCont.SubscribeFilterLogs(ctx, wsEcl, collectedEvents(chain.DaoCont, TokenEvents), logsCh)
+	if err != nil {
+		return err
+	}
+	defer daoSub.Unsubscribe()
+
+	log.Println("Log subscription started")
+	for {
+		select {
+		case <-ctx.Done(): // shutting down or the lease was lost
+			log.Println("Log subscription stopped")
+			return nil
+		case err = <-govSub.Err():
+			return err
+		case err = <-daoSub.Err():
+			return err
+		case eLog := <-logsCh:
+			if err = HandleLog(ctx, eLog); err != nil {
+				deadLetter(ctx, SourceStreamer, eLog, err)
+			}
+		}
+	}
+}
+
+// sleep Wait for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// HandleLog Decode the log with the event matching its address and signature hash and save it.
+func HandleLog(ctx context.Context, eLog types.Log) error {
+	if len(eLog.Topics) == 0 {
+		return chain.Permanent(errors.New(fmt.Sprintf(boraLabsErr.FailedParseLogData, "no topics")))
+	}
+	cont, err := chain.ContractByAddress(eLog.Address)
+	if err != nil {
+		return chain.Permanent(err)
+	}
+	evtName, err := cont.EventName(eLog.Topics[0])
+	if err != nil {
+		return chain.Permanent(err)
+	}
+
+	evt := chain.Event{Cont: cont}
+	if evt, err = evt.New(evtName); err != nil {
+		return err
+	}
+	if !eLog.Removed {
+		if err = evt.Decode(eLog); err != nil {
+			return chain.Permanent(errors.New(fmt.Sprintf(boraLabsErr.FailedParseLogData, err)))
+		}
+	}
+	if err = evt.SaveLog(ctx, "", eLog); err != nil {
+		return err
+	}
+	metrics.LogsProcessed.WithLabelValues(cont.Name(), evtName).Inc()
+	if evtName == chain.EventNameProposalCreated {
+		wakeProposalScheduler()
+	}
+	return nil
+}
@@ -6,9 +6,10 @@ import (
 	"boralabs/pkg/datastore/mongodb"
 	boraLabsErr "boralabs/pkg/error"
 	"boralabs/pkg/notification"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/core/types"
-	"log"
 	"time"
 )
 
@@ -23,7 +24,7 @@ func (p *VoteUpdater) Default() {
 	p.CreatedAt = time.Now()
 }
 
-func (p *VoteUpdater) Update(startBlock uint64) {
+func (p *VoteUpdater) Update(ctx context.Context) {
 	defer func() {
 		if r := recover(); r != nil {
 			notification.SendAll(fmt.Sprintf("Panic Vote AppendSave %v", r))
@@ -37,31 +38,49 @@ func (p *VoteUpdater) Update(startBlock uint64) {
 	}
 
 	var logs []types.Log
-	from := mongodb.CalcFromBlock(collectionNameVote)
-	if startBlock != 0 {
-		from = startBlock
+	from, err := mongodb.StartBlock(ctx, chain.GovCont.Address(), chain.EventNameVoteCast, chain.GovCont.FromBlock())
+	if err != nil {
+		panic(err)
 	}
-	logs, err = chain.GovCont.FilterLogs(evt.Signature, from)
+	// only confirmed blocks, like the collector, a vote in a newer block is saved by the collector later
+	to, err := chain.GovCont.SafeBlockNumber(ctx)
+	if err != nil {
+		panic(err)
+	}
+	if from > to {
+		return
+	}
+	logs, err = chain.GovCont.FilterLogs(ctx, from, to, evt.Name)
 	if err != nil {
 		panic(err)
 	}
 
+	// the logs that failed transiently are tried again, those still failing after the last try are dead-lettered once
 	defaultTerm := 1 * time.Second
-	tryCnt := 1
-	errCnt := 0
-	for tryCnt <= retryLimit {
+	var failed []failedLog
+	for tryCnt := 1; tryCnt <= retryLimit && len(logs) > 0; tryCnt++ {
+		failed = nil
 		for _, eLog := range logs {
-			if err = chain.GovCont.UnpackLogData(evt.Out, evt.Name, eLog); err != nil {
-				log.Println(fmt.Sprintf(boraLabsErr.FailedParseLogData, err))
+			if err = evt.Decode(eLog); err != nil {
+				deadLetter(ctx, SourceVoteUpdater, eLog, chain.Permanent(errors.New(fmt.Sprintf(boraLabsErr.FailedParseLogData, err))))
+				continue
 			}
-			err = evt.SaveLog(p.ProposalId, eLog)
-			if err != nil {
-				errCnt++
-				panic(err)
+			err = evt.SaveLog(ctx, p.ProposalId, eLog)
+			if chain.IsPermanentErr(err) {
+				deadLetter(ctx, SourceVoteUpdater, eLog, err)
+			} else if err != nil {
+				failed = append(failed, failedLog{eLog, err})
 			}
 		}
-		tryCnt++
-		time.Sleep(defaultTerm)
-		defaultTerm = defaultTerm + defaultTerm // 1 - 2 - 4
+
+		logs = nil
+		for _, f := range failed {
+			logs = append(logs, f.log)
+		}
+		if len(logs) > 0 && tryCnt < retryLimit {
+			sleep(ctx, defaultTerm)
+			defaultTerm = defaultTerm + defaultTerm // 1 - 2 - 4
+		}
 	}
+	deadLetterAll(ctx, SourceVoteUpdater, failed)
 }
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+// ArchivedLog Raw governor/token log with its decoded arguments, unique by tx hash and log index.
+// The derived collections can be rebuilt from the logs that were not removed.
+type ArchivedLog struct {
+	Contract       string    `bson:"contract" json:"contract"`
+	Event          string    `bson:"event" json:"event"`
+	Address        string    `bson:"address" json:"address"`
+	Topics         []string  `bson:"topics" json:"topics"`
+	Data           string    `bson:"data" json:"data"`
+	Args           bson.M    `bson:"args" json:"args"`
+	TxHash         string    `bson:"tx_hash" json:"tx_hash"`
+	TxIndex        uint      `bson:"tx_index" json:"tx_index"`
+	LogIndex       uint      `bson:"log_index" json:"log_index"`
+	BlockNumber    uint64    `bson:"block_number" json:"block_number"`
+	BlockHash      string    `bson:"block_hash" json:"block_hash"`
+	BlockCreatedAt time.Time `bson:"block_created_at" json:"block_created_at"`
+	Removed        bool      `bson:"removed" json:"removed"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Log The chain log the archived log was made from.
+func (l ArchivedLog) Log() types.Log {
+	topics := make([]common.Hash, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i] = common.HexToHash(topic)
+	}
+	data, _ := hexutil.Decode(l.Data)
+	return types.Log{
+		Address:     common.HexToAddress(l.Address),
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: l.BlockNumber,
+		TxHash:      common.HexToHash(l.TxHash),
+		TxIndex:     l.TxIndex,
+		BlockHash:   common.HexToHash(l.BlockHash),
+		Index:       l.LogIndex,
+		Removed:     l.Removed,
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DelegateChangedLog DelegateChanged Log Data
+// event DelegateChanged(address indexed delegator, address indexed fromDelegate, address indexed toDelegate)
+type DelegateChangedLog struct {
+	Delegator    common.Address `bson:"delegator" json:"delegator,omitempty"`
+	FromDelegate common.Address `bson:"from_delegate" json:"from_delegate,omitempty"`
+	ToDelegate   common.Address `bson:"to_delegate" json:"to_delegate,omitempty"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+)
+
+// DelegateVotesChangedLog DelegateVotesChanged Log Data
+// event DelegateVotesChanged(address indexed delegate, uint256 previousBalance, uint256 newBalance)
+type DelegateVotesChangedLog struct {
+	Delegate        common.Address `bson:"delegate" json:"delegate,omitempty"`
+	PreviousBalance *big.Int       `bson:"previous_balance" json:"previous_balance,omitempty"`
+	NewBalance      *big.Int       `bson:"new_balance" json:"new_balance,omitempty"`
+}
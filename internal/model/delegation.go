@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+)
+
+// Delegation Current delegate of a token holder.
+type Delegation struct {
+	Delegator        string    `bson:"delegator" json:"delegator"`
+	Delegate         string    `bson:"delegate" json:"delegate"`
+	PreviousDelegate string    `bson:"previous_delegate" json:"previous_delegate"`
+	BlockNumber      uint64    `bson:"block_number" json:"block_number"`
+	LogIndex         uint      `bson:"log_index" json:"log_index"`
+	BlockHash        string    `bson:"block_hash" json:"block_hash"`
+	TxHash           string    `bson:"tx_hash" json:"tx_hash"`
+	BlockCreatedAt   time.Time `bson:"block_created_at" json:"block_created_at"`
+	UpdatedAt        time.Time `bson:"updated_at" json:"-"`
+}
+
+// DelegateVotes Voting power change of a delegate.
+type DelegateVotes struct {
+	Delegate        string    `bson:"delegate" json:"delegate"`
+	PreviousBalance string    `bson:"previous_balance" json:"previous_balance"`
+	NewBalance      string    `bson:"new_balance" json:"new_balance"`
+	BlockNumber     uint64    `bson:"block_number" json:"block_number"`
+	LogIndex        uint      `bson:"log_index" json:"log_index"`
+	BlockHash       string    `bson:"block_hash" json:"block_hash"`
+	TxHash          string    `bson:"tx_hash" json:"tx_hash"`
+	BlockCreatedAt  time.Time `bson:"block_created_at" json:"block_created_at"`
+}
+
+// Delegate Latest voting power of a delegate with the number of accounts delegating to it.
+type Delegate struct {
+	Delegate       string `bson:"_id" json:"delegate"`
+	VotingPower    string `bson:"voting_power" json:"voting_power"`
+	DelegatorCount int64  `bson:"delegator_count" json:"delegator_count"`
+	BlockNumber    uint64 `bson:"block_number" json:"block_number"`
+}
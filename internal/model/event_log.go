@@ -0,0 +1,19 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+// EventLog Log of an event without a custom handler, its arguments decoded generically from the abi.
+type EventLog struct {
+	Event          string    `bson:"event" json:"event"`
+	Address        string    `bson:"address" json:"address"`
+	Args           bson.M    `bson:"args" json:"args"`
+	TxHash         string    `bson:"tx_hash" json:"tx_hash"`
+	LogIndex       uint      `bson:"log_index" json:"log_index"`
+	BlockNumber    uint64    `bson:"block_number" json:"block_number"`
+	BlockHash      string    `bson:"block_hash" json:"block_hash"`
+	BlockCreatedAt time.Time `bson:"block_created_at" json:"block_created_at"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// FailedLog Log that could not be decoded or saved, retried with backoff until it succeeds or is discarded.
+// A permanent failure is only retried from the admin API.
+type FailedLog struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Source      string             `bson:"source" json:"source"`
+	Contract    string             `bson:"contract,omitempty" json:"contract"`
+	Event       string             `bson:"event,omitempty" json:"event"`
+	TxHash      string             `bson:"tx_hash" json:"tx_hash"`
+	LogIndex    uint               `bson:"log_index" json:"log_index"`
+	BlockNumber uint64             `bson:"block_number" json:"block_number"`
+	Log         types.Log          `bson:"log" json:"log"`
+	Error       string             `bson:"error" json:"error"`
+	Permanent   bool               `bson:"permanent" json:"permanent"`
+	Attempts    int                `bson:"attempts" json:"attempts"`
+	NextRetryAt *time.Time         `bson:"next_retry_at,omitempty" json:"next_retry_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	AlertedAt   *time.Time         `bson:"alerted_at,omitempty" json:"alerted_at,omitempty"`
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"math/big"
+	"time"
+)
+
+// GovernanceParameterLog Log data of a governor configuration event.
+type GovernanceParameterLog interface {
+	Values() (oldValue, newValue *big.Int)
+}
+
+// VotingDelaySetLog VotingDelaySet Log Data
+// event VotingDelaySet(uint256 oldVotingDelay, uint256 newVotingDelay)
+type VotingDelaySetLog struct {
+	OldVotingDelay *big.Int `bson:"old_voting_delay" json:"old_voting_delay,omitempty"`
+	NewVotingDelay *big.Int `bson:"new_voting_delay" json:"new_voting_delay,omitempty"`
+}
+
+func (l *VotingDelaySetLog) Values() (*big.Int, *big.Int) {
+	return l.OldVotingDelay, l.NewVotingDelay
+}
+
+// VotingPeriodSetLog VotingPeriodSet Log Data
+// event VotingPeriodSet(uint256 oldVotingPeriod, uint256 newVotingPeriod)
+type VotingPeriodSetLog struct {
+	OldVotingPeriod *big.Int `bson:"old_voting_period" json:"old_voting_period,omitempty"`
+	NewVotingPeriod *big.Int `bson:"new_voting_period" json:"new_voting_period,omitempty"`
+}
+
+func (l *VotingPeriodSetLog) Values() (*big.Int, *big.Int) {
+	return l.OldVotingPeriod, l.NewVotingPeriod
+}
+
+// ProposalThresholdSetLog ProposalThresholdSet Log Data
+// event ProposalThresholdSet(uint256 oldProposalThreshold, uint256 newProposalThreshold)
+type ProposalThresholdSetLog struct {
+	OldProposalThreshold *big.Int `bson:"old_proposal_threshold" json:"old_proposal_threshold,omitempty"`
+	NewProposalThreshold *big.Int `bson:"new_proposal_threshold" json:"new_proposal_threshold,omitempty"`
+}
+
+func (l *ProposalThresholdSetLog) Values() (*big.Int, *big.Int) {
+	return l.OldProposalThreshold, l.NewProposalThreshold
+}
+
+// QuorumNumeratorUpdatedLog QuorumNumeratorUpdated Log Data
+// event QuorumNumeratorUpdated(uint256 oldQuorumNumerator, uint256 newQuorumNumerator)
+type QuorumNumeratorUpdatedLog struct {
+	OldQuorumNumerator *big.Int `bson:"old_quorum_numerator" json:"old_quorum_numerator,omitempty"`
+	NewQuorumNumerator *big.Int `bson:"new_quorum_numerator" json:"new_quorum_numerator,omitempty"`
+}
+
+func (l *QuorumNumeratorUpdatedLog) Values() (*big.Int, *big.Int) {
+	return l.OldQuorumNumerator, l.NewQuorumNumerator
+}
+
+// GovernanceParameterChange Change of a governor parameter.
+type GovernanceParameterChange struct {
+	Parameter      string    `bson:"parameter" json:"parameter"`
+	Event          string    `bson:"event" json:"event"`
+	OldValue       string    `bson:"old_value" json:"old_value"`
+	NewValue       string    `bson:"new_value" json:"new_value"`
+	BlockNumber    uint64    `bson:"block_number" json:"block_number"`
+	LogIndex       uint      `bson:"log_index" json:"log_index"`
+	BlockHash      string    `bson:"block_hash" json:"block_hash"`
+	TxHash         string    `bson:"tx_hash" json:"tx_hash"`
+	BlockCreatedAt time.Time `bson:"block_created_at" json:"block_created_at"`
+}
+
+// GovernanceSettings Current governor settings read from the contracts.
+type GovernanceSettings struct {
+	Name              string          `json:"name"`
+	Version           string          `json:"version"`
+	VotingDelay       string          `json:"voting_delay"`
+	VotingPeriod      string          `json:"voting_period"`
+	ProposalThreshold string          `json:"proposal_threshold"`
+	QuorumNumerator   string          `json:"quorum_numerator"`
+	QuorumDenominator string          `json:"quorum_denominator"`
+	CountingMode      string          `json:"counting_mode"`
+	ClockMode         string          `json:"clock_mode"`
+	Token             GovernanceToken `json:"token"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// GovernanceToken Voting token of the governor.
+type GovernanceToken struct {
+	Address     string `json:"address"`
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol"`
+	Decimals    uint8  `json:"decimals"`
+	TotalSupply string `json:"total_supply"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import (
+	"time"
+)
+
+// IndexedBlock Hash of a block the collector processed, used to detect chain reorganizations.
+type IndexedBlock struct {
+	BlockNumber uint64    `bson:"block_number" json:"block_number"`
+	BlockHash   string    `bson:"block_hash" json:"block_hash"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
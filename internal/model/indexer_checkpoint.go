@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+)
+
+// IndexerCheckpoint Last block fully processed by the collector for an event of a contract.
+type IndexerCheckpoint struct {
+	Contract    string    `bson:"contract" json:"contract"`
+	Event       string    `bson:"event" json:"event"`
+	BlockNumber uint64    `bson:"block_number" json:"block_number"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
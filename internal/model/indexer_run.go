@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// IndexerRun Outcome of the last collector runs for an event of a contract.
+type IndexerRun struct {
+	Contract      string     `bson:"contract" json:"contract"`
+	Event         string     `bson:"event" json:"event"`
+	LastRunAt     time.Time  `bson:"last_run_at" json:"last_run_at"`
+	LastSuccessAt *time.Time `bson:"last_success_at,omitempty" json:"last_success_at,omitempty"`
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `bson:"last_error_at,omitempty" json:"last_error_at,omitempty"`
+}
+
+// IndexerStatus Progress of the indexer for an event of a contract, as reported by the status API.
+type IndexerStatus struct {
+	Contract           string     `json:"contract"`
+	Address            string     `json:"address"`
+	Event              string     `json:"event"`
+	LastProcessedBlock *uint64    `json:"last_processed_block"`
+	ChainHead          uint64     `json:"chain_head"`
+	LagBlocks          uint64     `json:"lag_blocks"`
+	LagSeconds         uint64     `json:"lag_seconds"`
+	LastSuccessAt      *time.Time `json:"last_success_at"`
+	LastError          string     `json:"last_error"`
+	LastErrorAt        *time.Time `json:"last_error_at"`
+	FailedLogs         int64      `json:"failed_logs"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+)
+
+// Lease Lock held by one instance until it expires, renewed by the holder with a heartbeat.
+type Lease struct {
+	Name      string    `bson:"_id" json:"name"`
+	Holder    string    `bson:"holder" json:"holder"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"math/big"
+)
+
+// ProposalCanceledLog ProposalCanceled Log Data
+// event ProposalCanceled(uint256 proposalId)
+type ProposalCanceledLog struct {
+	ProposalId *big.Int `bson:"proposal_id" json:"proposal_id,omitempty"`
+}
@@ -35,6 +35,8 @@ type MongoProposalCreatedLog struct {
 	VoteEnd        time.Time `bson:"vote_end" json:"vote_end,omitempty"`
 	Description    string    `bson:"description" json:"description,omitempty"`
 	Log            types.Log `bson:"log"`
+	BlockNumber    uint64    `bson:"block_number" json:"block_number"`
+	BlockHash      string    `bson:"block_hash" json:"block_hash"`
 	BlockCreatedAt time.Time `bson:"block_created_at" json:"block_created_at"`
 	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
 }
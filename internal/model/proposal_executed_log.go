@@ -0,0 +1,11 @@
+package model
+
+import (
+	"math/big"
+)
+
+// ProposalExecutedLog ProposalExecuted Log Data
+// event ProposalExecuted(uint256 proposalId)
+type ProposalExecutedLog struct {
+	ProposalId *big.Int `bson:"proposal_id" json:"proposal_id,omitempty"`
+}
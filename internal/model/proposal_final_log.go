@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+	"time"
+)
+
+// MongoProposalFinalLog ProposalCanceled or ProposalExecuted log, the event tells which.
+type MongoProposalFinalLog struct {
+	Event          string    `bson:"event" json:"event"`
+	ProposalId     string    `bson:"proposal_id" json:"proposal_id,omitempty"`
+	TxHash         string    `bson:"tx_hash" json:"tx_hash,omitempty"`
+	Log            types.Log `bson:"log"`
+	BlockNumber    uint64    `bson:"block_number" json:"block_number"`
+	BlockHash      string    `bson:"block_hash" json:"block_hash"`
+	BlockCreatedAt time.Time `bson:"block_created_at" json:"block_created_at"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -18,5 +18,7 @@ type VoteCast struct {
 	Weight        uint8     `bson:"weight" json:"-"`                 // Percentage of DAO tokens owned by the user compared to the total DAO tokens.
 	Status        uint8     `bson:"status" json:"status"`
 	TxHash        string    `bson:"tx_hash" json:"txhash"`
+	BlockNumber   uint64    `bson:"block_number,omitempty" json:"-"`
+	BlockHash     string    `bson:"block_hash,omitempty" json:"-"`
 	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
 }
@@ -17,10 +17,13 @@ type VoteCastLog struct {
 }
 
 type MongoVoteCastLog struct {
-	Voter      string    `bson:"voter,omitempty"`
-	ProposalId string    `bson:"proposal_id,omitempty"`
-	Support    uint8     `bson:"support,omitempty"`
-	Weight     uint8     `bson:"weight,omitempty"` // voting power
-	Reason     string    `bson:"reason,omitempty"`
-	CreatedAt  time.Time `bson:"created_at"`
+	Voter       string    `bson:"voter,omitempty"`
+	ProposalId  string    `bson:"proposal_id,omitempty"`
+	Support     uint8     `bson:"support,omitempty"`
+	Weight      uint8     `bson:"weight,omitempty"` // voting power
+	Reason      string    `bson:"reason,omitempty"`
+	TxHash      string    `bson:"tx_hash,omitempty"`
+	BlockNumber uint64    `bson:"block_number"`
+	BlockHash   string    `bson:"block_hash,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
 }
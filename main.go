@@ -1,104 +1,139 @@
 package main
 
 import (
-	_ "boralabs/config"
+	"boralabs/config"
+	"boralabs/internal/chain"
 	"boralabs/internal/event_logger"
 	"boralabs/pkg/datastore/mongodb"
+	boraLabsErr "boralabs/pkg/error"
 	"boralabs/pkg/notification"
-	"boralabs/pkg/router"
 	"boralabs/pkg/util"
 	"context"
+	"errors"
 	"fmt"
-	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 	"log"
-	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
 func init() {
-	mongodb.New()
 	notification.BaseLoggers = append(notification.BaseLoggers, &notification.SlackLogger)
-	go eventCollect()
 }
 
-// RateLimiter Define RateLimiter struct
-type RateLimiter struct {
-	limiter *rate.Limiter
-}
-
-// NewRateLimiter Initialize RateLimiter with NewRateLimiter function
-func NewRateLimiter(r rate.Limit, b int) *RateLimiter {
-	return &RateLimiter{
-		limiter: rate.NewLimiter(r, b),
-	}
-}
+// eventCollect Run the collection jobs every 30 seconds until the context is done.
+func eventCollect(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
 
-// Middleware Implement Rate Limiting middleware with Middleware function
-func (rl *RateLimiter) Middleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if !rl.limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
-			c.Abort()
+	// only the replica holding the lease collects
+	leader := event_logger.NewLeader(event_logger.LeaseIndexer)
+	leader.Start(ctx)
+	defer leader.Release(ctx)
+	collect := func() {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Println(err)
+				util.PrintStackTrace()
+			}
+		}()
+		term, ok := leader.Term() // the run stops when the lease is lost
+		if !ok {
 			return
 		}
-		c.Next()
+		event_logger.Collector{}.Collect(term)
+		event_logger.DelegationCollector{}.Collect(term)
+		event_logger.FailedLogRetrier{}.Retry(term)
 	}
-}
-
-func eventCollect() {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
 
 	// init
-	event_logger.Collector{}.Collect()
+	collect()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	wg.Add(1)
+	go func() { // the proposal states move at their start and end dates
+		defer wg.Done()
+		event_logger.ProposalScheduler{Leader: leader}.Run(ctx)
+	}()
+	if config.C.GetString("wsEndpoint") != "" {
+		wg.Add(1)
+		go func() { // new logs are saved right away, polling catches up on the confirmed blocks
+			defer wg.Done()
+			event_logger.Streamer{Leader: leader}.Stream(ctx)
+		}()
+	}
 	for {
 		select {
+		case <-ctx.Done():
+			log.Println("Indexer stopped")
+			return
 		case <-ticker.C:
-			func() {
-				defer func() {
-					if err := recover(); err != nil {
-						log.Println(err)
-						util.PrintStackTrace()
-					}
-				}()
-				event_logger.Collector{}.Collect()
-			}()
+			collect()
 		}
 	}
 }
 
 func main() {
-	defer func() {
-		if err := mongodb.Conn.Disconnect(context.TODO()); err != nil {
-			panic(err)
-		}
-	}()
-
 	runtime.GOMAXPROCS(runtime.NumCPU())
 	log.SetFlags(log.LstdFlags | log.Llongfile)
 
-	// Initialize Rate Limiter
-	rateLimiter := NewRateLimiter(5, 10)
-
-	// Apply Rate Limiting middleware
-	router.E.Use(rateLimiter.Middleware())
+	// without a subcommand everything runs in one process, as before the subcommands existed
+	name, args := "serve", append([]string{"-migrate", "-index"}, os.Args[1:]...)
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		name, args = os.Args[1], os.Args[2:]
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	// SIGTERM/SIGINT cancel the context, the command finishes its current work and returns
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := cmd.run(ctx, args); err != nil {
+		log.Fatalf("%s :: %v\n", cmd.name, err)
+	}
+}
 
-	s := &http.Server{
-		Addr:           fmt.Sprintf(":%s", port()),
-		Handler:        router.E, // set router
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   30 * time.Second,
-		MaxHeaderBytes: 1 << 20, // 1 MB
-		IdleTimeout:    120 * time.Second,
+// rewindCheckpoints Rewind the checkpoint of each Event=block pair so the collector starts again at that block.
+func rewindCheckpoints(ctx context.Context, rewind string) error {
+	if rewind == "" {
+		return nil
 	}
-	http.DefaultClient.Timeout = 10 * time.Second // set default http timeout
+	for _, pair := range strings.Split(rewind, ",") {
+		evtName, block, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			return errors.New(fmt.Sprintf("Invalid rewind %s", pair))
+		}
+		blockNumber, err := strconv.ParseUint(block, 10, 64)
+		if err != nil {
+			return errors.New(fmt.Sprintf(boraLabsErr.WrongFromBlockNumber, block))
+		}
+		cont, err := chain.ContractByEvent(evtName)
+		if err != nil {
+			return err
+		}
+		if err = mongodb.RewindCheckpoint(ctx, cont.Address(), evtName, blockNumber); err != nil {
+			return err
+		}
+		log.Printf("Rewind checkpoint :: %s :: [BlockNumber - %d]\n", evtName, blockNumber)
+	}
+	return nil
+}
 
-	if err := s.ListenAndServe(); err != nil {
-		panic(err)
+// eventNames Event names of a comma separated list.
+func eventNames(events string) (evtNames []string) {
+	for _, evtName := range strings.Split(events, ",") {
+		if evtName = strings.TrimSpace(evtName); evtName != "" {
+			evtNames = append(evtNames, evtName)
+		}
 	}
+	return
 }
 
 func port() string {
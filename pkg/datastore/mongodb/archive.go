@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"boralabs/internal/model"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const CollectionEventLogs = "event_logs"
+
+// SaveArchivedLog Upsert the log by tx hash and log index. A log collected again after a reorg replaces its removed copy.
+func SaveArchivedLog(ctx context.Context, archived model.ArchivedLog) error {
+	archived.UpdatedAt = time.Now()
+	opt := options.Update().SetUpsert(true)
+	_, err := DB.Collection(CollectionEventLogs).UpdateOne(ctx, bson.D{
+		{Key: "tx_hash", Value: archived.TxHash},
+		{Key: "log_index", Value: archived.LogIndex},
+	}, bson.D{{Key: "$set", Value: archived}}, opt)
+	return err
+}
+
+// RemoveArchivedLogs Flag the logs from the given block number onwards as removed from the chain.
+func RemoveArchivedLogs(ctx context.Context, from uint64) error {
+	_, err := DB.Collection(CollectionEventLogs).UpdateMany(ctx, bson.D{
+		{Key: "block_number", Value: bson.D{{Key: "$gte", Value: from}}},
+		{Key: "removed", Value: false},
+	}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "removed", Value: true},
+		{Key: "updated_at", Value: time.Now()},
+	}}})
+	return err
+}
+
+// MinBlockNumber Lowest block_number of the documents of the collection matching the filter, found is false without any.
+func MinBlockNumber(ctx context.Context, collection string, filter bson.D) (blockNumber uint64, found bool, err error) {
+	var doc struct {
+		BlockNumber uint64 `bson:"block_number"`
+	}
+	filter = append(filter, bson.E{Key: "block_number", Value: bson.D{{Key: "$gt", Value: 0}}})
+	opt := options.FindOne().SetSort(bson.D{{Key: "block_number", Value: 1}}).SetProjection(bson.D{{Key: "block_number", Value: 1}})
+	err = DB.Collection(collection).FindOne(ctx, filter, opt).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return doc.BlockNumber, true, nil
+}
+
+func createArchiveIndexes(ctx context.Context) error {
+	_, err := DB.Collection(CollectionEventLogs).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tx_hash", Value: 1}, {Key: "log_index", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}},
+		},
+	})
+	return err
+}
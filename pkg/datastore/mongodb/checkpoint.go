@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"boralabs/internal/model"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const CollectionCheckpoints = "indexer_checkpoints"
+
+// GetCheckpoint Checkpoint of the event, found is false when the event was never collected.
+func GetCheckpoint(ctx context.Context, contract, event string) (checkpoint model.IndexerCheckpoint, found bool, err error) {
+	err = DB.Collection(CollectionCheckpoints).FindOne(ctx, bson.D{
+		{Key: "contract", Value: contract},
+		{Key: "event", Value: event},
+	}).Decode(&checkpoint)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return checkpoint, false, nil
+		}
+		return checkpoint, false, err
+	}
+	return checkpoint, true, nil
+}
+
+// createCheckpointIndexes One checkpoint per contract event, every read and upsert looks it up by that key.
+func createCheckpointIndexes(ctx context.Context) error {
+	_, err := DB.Collection(CollectionCheckpoints).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "contract", Value: 1}, {Key: "event", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// StartBlock Block to resume collecting the event from: the block after the checkpoint, or fromBlock of the contract.
+func StartBlock(ctx context.Context, contract, event string, fromBlock uint64) (uint64, error) {
+	checkpoint, found, err := GetCheckpoint(ctx, contract, event)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return fromBlock, nil
+	}
+	return checkpoint.BlockNumber + 1, nil
+}
+
+// SaveCheckpoint Store the last fully processed block of the event.
+func SaveCheckpoint(ctx context.Context, contract, event string, blockNumber uint64) error {
+	opt := options.Update().SetUpsert(true)
+	_, err := DB.Collection(CollectionCheckpoints).UpdateOne(ctx, bson.D{
+		{Key: "contract", Value: contract},
+		{Key: "event", Value: event},
+	}, bson.D{{Key: "$set", Value: model.IndexerCheckpoint{
+		Contract:    contract,
+		Event:       event,
+		BlockNumber: blockNumber,
+		UpdatedAt:   time.Now(),
+	}}}, opt)
+	return err
+}
+
+// RewindCheckpoint Collect the event again starting at the given block.
+func RewindCheckpoint(ctx context.Context, contract, event string, blockNumber uint64) error {
+	if blockNumber == 0 {
+		_, err := DB.Collection(CollectionCheckpoints).DeleteOne(ctx, bson.D{
+			{Key: "contract", Value: contract},
+			{Key: "event", Value: event},
+		})
+		return err
+	}
+	return SaveCheckpoint(ctx, contract, event, blockNumber-1)
+}
+
+// RewindCheckpoints Move every checkpoint at or past the given block back before it.
+func RewindCheckpoints(ctx context.Context, blockNumber uint64) error {
+	if blockNumber == 0 {
+		_, err := DB.Collection(CollectionCheckpoints).DeleteMany(ctx, bson.D{})
+		return err
+	}
+	_, err := DB.Collection(CollectionCheckpoints).UpdateMany(ctx, bson.D{
+		{Key: "block_number", Value: bson.D{{Key: "$gte", Value: blockNumber}}},
+	}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "block_number", Value: blockNumber - 1},
+		{Key: "updated_at", Value: time.Now()},
+	}}})
+	return err
+}
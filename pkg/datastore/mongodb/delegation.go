@@ -0,0 +1,31 @@
+package mongodb
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CollectionDelegations          = "delegations"
+	CollectionDelegateVotesHistory = "delegate_votes_history"
+)
+
+// CreateDelegationIndexes Create the indexes of the delegation collections, suffix names their rebuilt copies.
+// A delegator has a single delegation, the voting power history is upserted by log and read newest first.
+func CreateDelegationIndexes(ctx context.Context, suffix string) error {
+	_, err := DB.Collection(CollectionDelegations+suffix).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "delegator", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "delegate", Value: 1}, {Key: "block_number", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = DB.Collection(CollectionDelegateVotesHistory+suffix).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tx_hash", Value: 1}, {Key: "log_index", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "delegate", Value: 1}, {Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}}},
+		{Keys: bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}}},
+	})
+	return err
+}
@@ -0,0 +1,111 @@
+package mongodb
+
+import (
+	"boralabs/internal/model"
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const CollectionFailedLogs = "failed_logs"
+
+// RecordFailedLog Upsert the failure of the log by tx hash and log index.
+// Only a retry counts as an attempt, the same log failing again in the collector or an updater is the first attempt still.
+func RecordFailedLog(ctx context.Context, failed model.FailedLog, isRetry bool) (model.FailedLog, error) {
+	now := time.Now()
+	onInsert := bson.D{{Key: "created_at", Value: now}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "source", Value: failed.Source},
+			{Key: "contract", Value: failed.Contract},
+			{Key: "event", Value: failed.Event},
+			{Key: "block_number", Value: failed.BlockNumber},
+			{Key: "log", Value: failed.Log},
+			{Key: "error", Value: failed.Error},
+			{Key: "permanent", Value: failed.Permanent},
+			{Key: "updated_at", Value: now},
+		}},
+	}
+	if isRetry {
+		update = append(update, bson.E{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}})
+	} else {
+		onInsert = append(onInsert, bson.E{Key: "attempts", Value: 1})
+	}
+	update = append(update, bson.E{Key: "$setOnInsert", Value: onInsert})
+
+	opt := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := DB.Collection(CollectionFailedLogs).FindOneAndUpdate(ctx, bson.D{
+		{Key: "tx_hash", Value: failed.TxHash},
+		{Key: "log_index", Value: failed.LogIndex},
+	}, update, opt).Decode(&failed)
+	return failed, err
+}
+
+// MarkFailedLogAlerted Remember the failed log was alerted, false when it already was.
+func MarkFailedLogAlerted(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	res, err := DB.Collection(CollectionFailedLogs).UpdateOne(ctx, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "alerted_at", Value: bson.D{{Key: "$exists", Value: false}}},
+	}, bson.D{{Key: "$set", Value: bson.D{{Key: "alerted_at", Value: time.Now()}}}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// ScheduleFailedLog Set the time of the next retry of the failed log.
+func ScheduleFailedLog(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := DB.Collection(CollectionFailedLogs).UpdateByID(ctx, id, bson.D{
+		{Key: "$set", Value: bson.D{{Key: "next_retry_at", Value: at}}},
+	})
+	return err
+}
+
+// DueFailedLogs Transient failed logs whose retry is due, oldest block first.
+func DueFailedLogs(ctx context.Context, maxAttempts int, limit int64) (failed []model.FailedLog, err error) {
+	opt := options.Find().SetSort(bson.D{{Key: "block_number", Value: 1}, {Key: "log_index", Value: 1}}).SetLimit(limit)
+	cursor, err := DB.Collection(CollectionFailedLogs).Find(ctx, bson.D{
+		{Key: "permanent", Value: bson.D{{Key: "$ne", Value: true}}},
+		{Key: "attempts", Value: bson.D{{Key: "$lt", Value: maxAttempts}}},
+		{Key: "next_retry_at", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+	}, opt)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &failed)
+	return
+}
+
+// GetFailedLog Failed log by id.
+func GetFailedLog(ctx context.Context, id primitive.ObjectID) (failed model.FailedLog, err error) {
+	err = DB.Collection(CollectionFailedLogs).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&failed)
+	return
+}
+
+// DeleteFailedLog Remove the failed log after a successful retry or when it is discarded.
+func DeleteFailedLog(ctx context.Context, id primitive.ObjectID) error {
+	res, err := DB.Collection(CollectionFailedLogs).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err == nil && res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+// CountFailedLogs Number of failed logs of the event waiting in the queue.
+func CountFailedLogs(ctx context.Context, contract, event string) (int64, error) {
+	return DB.Collection(CollectionFailedLogs).CountDocuments(ctx, bson.D{
+		{Key: "contract", Value: contract},
+		{Key: "event", Value: event},
+	})
+}
+
+func createFailedLogIndexes(ctx context.Context) error {
+	_, err := DB.Collection(CollectionFailedLogs).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tx_hash", Value: 1}, {Key: "log_index", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,48 @@
+package mongodb
+
+import (
+	"boralabs/internal/model"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const CollectionIndexerRuns = "indexer_runs"
+
+// RecordIndexerRun Store the outcome of a collector run for the event, the last error is kept after a successful run.
+func RecordIndexerRun(ctx context.Context, contract, event string, runErr error) error {
+	now := time.Now()
+	set := bson.D{{Key: "last_run_at", Value: now}}
+	if runErr == nil {
+		set = append(set, bson.E{Key: "last_success_at", Value: now})
+	} else {
+		set = append(set,
+			bson.E{Key: "last_error", Value: runErr.Error()},
+			bson.E{Key: "last_error_at", Value: now},
+		)
+	}
+	opt := options.Update().SetUpsert(true)
+	_, err := DB.Collection(CollectionIndexerRuns).UpdateOne(ctx, bson.D{
+		{Key: "contract", Value: contract},
+		{Key: "event", Value: event},
+	}, bson.D{{Key: "$set", Value: set}}, opt)
+	return err
+}
+
+// GetIndexerRun Last runs of the event, found is false when the collector never ran for it.
+func GetIndexerRun(ctx context.Context, contract, event string) (run model.IndexerRun, found bool, err error) {
+	err = DB.Collection(CollectionIndexerRuns).FindOne(ctx, bson.D{
+		{Key: "contract", Value: contract},
+		{Key: "event", Value: event},
+	}).Decode(&run)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return run, false, nil
+		}
+		return run, false, err
+	}
+	return run, true, nil
+}
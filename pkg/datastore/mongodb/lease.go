@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"boralabs/internal/model"
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+const CollectionLeases = "leases"
+
+// AcquireLease Take or renew the lease for the holder. It fails without error while another holder's lease is not expired.
+func AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	opt := options.Update().SetUpsert(true)
+	_, err := DB.Collection(CollectionLeases).UpdateOne(ctx, bson.D{
+		{Key: "_id", Value: name},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "holder", Value: holder}},
+			bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}}},
+		}},
+	}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "holder", Value: holder},
+		{Key: "expires_at", Value: now.Add(ttl)},
+		{Key: "updated_at", Value: now},
+	}}}, opt)
+	if err != nil {
+		if isDup(err) { // the upsert collided with the lease of another holder
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseLease Give up the lease so another instance takes over without waiting for it to expire.
+func ReleaseLease(ctx context.Context, name, holder string) error {
+	_, err := DB.Collection(CollectionLeases).DeleteOne(ctx, bson.D{
+		{Key: "_id", Value: name},
+		{Key: "holder", Value: holder},
+	})
+	return err
+}
+
+// GetLease Current lease, found is false when nobody holds it.
+func GetLease(ctx context.Context, name string) (lease model.Lease, found bool, err error) {
+	err = DB.Collection(CollectionLeases).FindOne(ctx, bson.D{{Key: "_id", Value: name}}).Decode(&lease)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return lease, false, nil
+		}
+		return lease, false, err
+	}
+	return lease, lease.ExpiresAt.After(time.Now()), nil
+}
+
+// createLeaseIndexes Expired leases are removed by MongoDB, a crashed holder leaves nothing behind.
+func createLeaseIndexes(ctx context.Context) error {
+	_, err := DB.Collection(CollectionLeases).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
@@ -2,20 +2,26 @@ package mongodb
 
 import (
 	"boralabs/config"
-	"boralabs/internal/model"
+	"boralabs/pkg/metrics"
 	"context"
 	"errors"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
+	"time"
+)
+
+const (
+	CollectionIndexedBlocks = "indexed_blocks"
+	CollectionVotes         = "votes"
 )
 
 var (
-	Conn               *mongo.Client
-	DB                 *mongo.Database
-	latestCollectBlock uint64
+	Conn *mongo.Client
+	DB   *mongo.Database
 )
 
 func New() {
@@ -26,7 +32,8 @@ func New() {
 	}
 	clientOptions := options.Client().
 		ApplyURI(fmt.Sprintf("mongodb://%s:%s", config.C.GetString("mongo.host"), config.C.GetString("mongo.port"))).
-		SetAuth(credential)
+		SetAuth(credential).
+		SetMonitor(commandMonitor())
 	Conn, err = mongo.Connect(context.TODO(), clientOptions)
 	if err != nil {
 		log.Fatal(err)
@@ -39,17 +46,101 @@ func New() {
 		log.Fatal(err)
 	}
 	DB = Conn.Database("boralabs")
-	createCollections([]string{
+	log.Println("MongoDB Connected")
+}
+
+// commandMonitor Observe the latency of every command sent to MongoDB.
+func commandMonitor() *event.CommandMonitor {
+	observe := func(command, status string, duration time.Duration) {
+		metrics.MongoDuration.WithLabelValues(command, status).Observe(duration.Seconds())
+	}
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			observe(e.CommandName, "ok", e.Duration)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			observe(e.CommandName, "error", e.Duration)
+		},
+	}
+}
+
+// Migrate Create the collections and the indexes of the indexer.
+func Migrate(ctx context.Context) error {
+	createCollections(ctx, []string{
 		"proposals",
+		CollectionVotes,
 		"proposal_created_logs",
 		"vote_cast_logs",
+		"proposal_canceled_logs",
+		"proposal_executed_logs",
+		CollectionDelegations,
+		CollectionDelegateVotesHistory,
+		"governance_parameter_changes",
+		CollectionIndexedBlocks,
+		CollectionCheckpoints,
+		CollectionIndexerRuns,
+		CollectionEventLogs,
+		CollectionFailedLogs,
+		CollectionLeases,
 	})
-	log.Println("MongoDB Connected")
+	if err := createArchiveIndexes(ctx); err != nil {
+		return errors.New(fmt.Sprintf("Failed CreateIndexes :: %s %v", CollectionEventLogs, err))
+	}
+	if err := createFailedLogIndexes(ctx); err != nil {
+		return errors.New(fmt.Sprintf("Failed CreateIndexes :: %s %v", CollectionFailedLogs, err))
+	}
+	if err := createLeaseIndexes(ctx); err != nil {
+		return errors.New(fmt.Sprintf("Failed CreateIndexes :: %s %v", CollectionLeases, err))
+	}
+	if err := CreateDelegationIndexes(ctx, ""); err != nil {
+		return errors.New(fmt.Sprintf("Failed CreateIndexes :: %s %v", CollectionDelegations, err))
+	}
+	if err := createCheckpointIndexes(ctx); err != nil {
+		return errors.New(fmt.Sprintf("Failed CreateIndexes :: %s %v", CollectionCheckpoints, err))
+	}
+	if err := createIndexedBlockIndexes(ctx); err != nil {
+		return errors.New(fmt.Sprintf("Failed CreateIndexes :: %s %v", CollectionIndexedBlocks, err))
+	}
+	if err := CreateVoteIndexes(ctx, ""); err != nil {
+		var e mongo.CommandError
+		if errors.As(err, &e) && e.Code == 11000 { // votes inserted twice before the index existed
+			log.Printf("Failed CreateIndexes :: %s, run rebuild to drop the duplicated votes %v\n", CollectionVotes, err)
+			return nil
+		}
+		return errors.New(fmt.Sprintf("Failed CreateIndexes :: %s %v", CollectionVotes, err))
+	}
+	return nil
+}
+
+// CreateVoteIndexes Create the indexes of the votes collection, suffix names its rebuilt copy.
+// A wallet has a single vote per proposal, the VoteCast handler upserts it by that key.
+func CreateVoteIndexes(ctx context.Context, suffix string) error {
+	_, err := DB.Collection(CollectionVotes+suffix).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "proposal_id", Value: 1}, {Key: "wallet_address", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Disconnect Close the connection.
+func Disconnect() {
+	if err := Conn.Disconnect(context.TODO()); err != nil {
+		log.Println(err)
+	}
 }
 
-func createCollections(collections []string) {
+// createIndexedBlockIndexes Blocks are upserted by number and the reorg check reads the newest ones.
+func createIndexedBlockIndexes(ctx context.Context) error {
+	_, err := DB.Collection(CollectionIndexedBlocks).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "block_number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func createCollections(ctx context.Context, collections []string) {
 	for _, collection := range collections {
-		if err := DB.CreateCollection(context.Background(), collection, nil); err != nil {
+		if err := DB.CreateCollection(ctx, collection, nil); err != nil {
 			var e mongo.CommandError
 			if errors.As(err, &e) && e.Code == 48 {
 				log.Printf("Exists Collection :: %s %v\n", collection, err)
@@ -92,38 +183,17 @@ func NextSequence(coll string) uint64 {
 	return uint64(lastId) + 1
 }
 
-func CalcFromBlock(collName string) (from uint64) {
-	from = config.C.GetUint64("fromBlock")
-	isRewind := config.C.GetBool("isRewind")
-	opt := options.Find().SetSort(bson.D{{"block_number", 1}})
-	find, err := DB.Collection(collName).Find(context.Background(),
-		bson.D{
-			{"state", bson.M{"$ne": "closed"}}, // exclude close state
-		}, opt)
-	if err != nil {
-		return from
-	}
-
-	if from > 0 && isRewind {
-		return from // If the isRewind environment variable or config value is set, it will be configured to the initial block number.
-	}
-
-	var proposals []model.Proposal
-	err = find.All(context.Background(), &proposals)
-	if err != nil || len(proposals) == 0 {
-		if latestCollectBlock > 0 {
-			from = latestCollectBlock
-		}
-		return from
-	}
-
-	if proposals[0].BlockNumber > 0 {
-		from = proposals[0].BlockNumber
-		latestCollectBlock = from
-	}
-	if from == 0 {
-		return from
-	}
+// RenameCollection Rename the source collection, replacing the target collection when dropTarget is set.
+func RenameCollection(ctx context.Context, source, target string, dropTarget bool) error {
+	return Conn.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "renameCollection", Value: fmt.Sprintf("%s.%s", DB.Name(), source)},
+		{Key: "to", Value: fmt.Sprintf("%s.%s", DB.Name(), target)},
+		{Key: "dropTarget", Value: dropTarget},
+	}).Err()
+}
 
-	return from - 1
+// IsNamespaceNotFoundErr Whether the command failed because the collection does not exist.
+func IsNamespaceNotFoundErr(err error) bool {
+	var e mongo.CommandError
+	return errors.As(err, &e) && e.Code == 26
 }
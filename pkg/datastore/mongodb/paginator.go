@@ -45,3 +45,39 @@ func (p *Paginator) Calculate(collName string, filter interface{}, sort bson.D)
 
 	return coll.Find(ctx, filter, &fOpt)
 }
+
+// CalculateAggregate Paginate the result of an aggregation pipeline.
+func (p *Paginator) CalculateAggregate(collName string, pipeline mongo.Pipeline) (*mongo.Cursor, error) {
+	ctx := context.Background()
+	coll := DB.Collection(collName)
+
+	// calculate total count
+	countPipeline := append(mongo.Pipeline{}, pipeline...)
+	countPipeline = append(countPipeline, bson.D{{Key: "$count", Value: "total"}})
+	countCursor, err := coll.Aggregate(ctx, countPipeline)
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		Total int64 `bson:"total"`
+	}
+	if err = countCursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	if len(counts) > 0 {
+		p.Total = counts[0].Total
+	}
+
+	// when total exceeds limit
+	if p.Total > p.Limit {
+		p.TotalPage = int64(math.Ceil(float64(p.Total) / float64(p.Limit)))
+	}
+
+	skip := (p.Page * p.Limit) - p.Limit
+	pagePipeline := append(mongo.Pipeline{}, pipeline...)
+	pagePipeline = append(pagePipeline,
+		bson.D{{Key: "$skip", Value: skip}},
+		bson.D{{Key: "$limit", Value: p.Limit}},
+	)
+	return coll.Aggregate(ctx, pagePipeline)
+}
@@ -4,10 +4,19 @@ const (
 	EmptyConfigValue     = "Empty value for config key\n"
 	InvalidEventName     = "Invalid event name %s\n"
 	WrongFromBlockNumber = "Failed convert fromBlock %s\n"
+	WrongStartBlock      = "Failed convert %s %s\n"
 	FailedNewContract    = "Failed new contract :: %v\n"
 	FailedParseLogData   = "Failed parse log data :: %v\n"
 	FailedSaveLogData    = "Failed save log data :: %v\n"
 	FailedUpdateLogData  = "Failed to update log data: %v\n"
-	FailedBlockByNumber  = "Failed blockByNumber :: %v\n"
+	FailedBlockByNumber  = "Failed headerByNumber :: %v\n"
 	FailedExistsProposal = "This is an proposal that already exists."
+	FailedContractCall   = "Failed contract call :: %s\n"
+	UnknownProposalState = "Unknown proposal state :: %v\n"
+	InvalidAddress       = "Invalid address"
+	OrphanedLog          = "Log of block %d (%s) is not on the canonical chain\n"
+	NotFoundProposal     = "Not found proposal %s"
+	ProposalNotIndexed   = "Proposal %s is not indexed yet, its ProposalCreated log was not collected"
+	ProposalNotConfirmed = "Proposal %s is stored, its chain data is attached once the ProposalCreated log is confirmed"
+	IncompleteArchive    = "%s holds logs from block %d but the archive of %v starts at block %d, rewind the checkpoints and collect the events again before rebuilding\n"
 )
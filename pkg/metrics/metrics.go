@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"strconv"
+	"time"
+)
+
+const namespace = "boralabs"
+
+var (
+	// IndexerLag Blocks between the chain head and the checkpoint of each collected event.
+	IndexerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "indexer_lag_blocks",
+		Help:      "Blocks between the chain head and the last processed block of the event.",
+	}, []string{"contract", "event"})
+
+	// LogsProcessed Logs saved by the indexer.
+	LogsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "indexer_logs_processed_total",
+		Help:      "Logs saved by the indexer.",
+	}, []string{"contract", "event"})
+
+	// LogsFailed Logs sent to the dead-letter queue, by the job which failed to save them.
+	LogsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "indexer_logs_failed_total",
+		Help:      "Logs which failed to be saved and were sent to the dead-letter queue.",
+	}, []string{"source"})
+
+	// RPCDuration Latency of the JSON-RPC calls.
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Latency of the JSON-RPC calls, failovers included.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// RPCErrors Failed JSON-RPC calls.
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_errors_total",
+		Help:      "JSON-RPC calls which failed on every endpoint or were rejected by the node.",
+	}, []string{"method"})
+
+	// MongoDuration Latency of the MongoDB commands.
+	MongoDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_duration_seconds",
+		Help:      "Latency of the MongoDB commands.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command", "status"})
+
+	// HTTPDuration Latency of the API requests.
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the API requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// RateLimited Requests rejected by the rate limiter.
+	RateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_rate_limited_total",
+		Help:      "Requests rejected by the rate limiter.",
+	})
+)
+
+// Handler Expose the metrics in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// Middleware Observe the latency of each request, labelled with the route pattern to keep the cardinality bounded.
+func Middleware(c *gin.Context) {
+	startedAt := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	HTTPDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(time.Since(startedAt).Seconds())
+}
+
+// ObserveRPC Record the latency and the result of a JSON-RPC call.
+func ObserveRPC(method string, startedAt time.Time, err error) {
+	RPCDuration.WithLabelValues(method).Observe(time.Since(startedAt).Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"boralabs/config"
+	"boralabs/internal/chain"
+	"boralabs/internal/event_logger"
+	"boralabs/pkg/datastore/mongodb"
+	"boralabs/pkg/router/rest"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+const (
+	readyCheckTimeout  = 5 * time.Second
+	defaultReadyMaxLag = 100
+	checkStatusOK      = "ok"
+	checkStatusFail    = "fail"
+	checkStatusSkipped = "skipped"
+)
+
+// readyCheck Outcome of one dependency check of /readyz.
+type readyCheck struct {
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// livez The process is up and serving requests, no dependency is checked so a restart does not follow an outage of MongoDB or the node.
+func livez(c *gin.Context) {
+	c.Status(http.StatusOK)
+}
+
+// readyz Whether the instance serves fresh data: MongoDB answers, the node answers on the expected chain and the indexer is not behind.
+// The load balancer stops routing to the instance while it answers 503.
+func readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyCheckTimeout)
+	defer cancel()
+
+	ready := true
+	checks := make(map[string]readyCheck)
+	run := func(name string, f func() (string, error)) {
+		startedAt := time.Now()
+		detail, err := f()
+		check := readyCheck{Status: checkStatusOK, Detail: detail, Duration: time.Since(startedAt).String()}
+		if err != nil {
+			ready = false
+			check.Status = checkStatusFail
+			check.Error = err.Error()
+		}
+		checks[name] = check
+	}
+
+	run("mongo", func() (string, error) {
+		return "", mongodb.Conn.Ping(ctx, nil)
+	})
+	run("rpc_chain_id", func() (string, error) {
+		chainID, err := chain.Pool.ChainID(ctx)
+		if err != nil {
+			return "", err
+		}
+		if expected := config.C.GetUint64("chainId"); expected != 0 && (!chainID.IsUint64() || chainID.Uint64() != expected) {
+			return chainID.String(), errors.New(fmt.Sprintf("expected chain id %d", expected))
+		}
+		return chainID.String(), nil
+	})
+	var head uint64
+	run("rpc_block_number", func() (detail string, err error) {
+		if head, err = chain.GovCont.BlockNumber(ctx); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d", head), nil
+	})
+	if checks["rpc_block_number"].Status == checkStatusOK {
+		run("indexer_lag", func() (string, error) {
+			return indexerLag(ctx, head)
+		})
+	} else {
+		checks["indexer_lag"] = readyCheck{Status: checkStatusSkipped, Detail: "chain head unknown"}
+	}
+
+	r := rest.Response{Context: c}
+	if !ready {
+		r.Code = http.StatusServiceUnavailable
+	}
+	r.BaseResponse.Data = gin.H{
+		"ready":  ready,
+		"checks": checks,
+	}
+	r.Json()
+}
+
+// indexerLag Fail when a checkpoint is more than readyMaxLag blocks behind the last confirmed block.
+func indexerLag(ctx context.Context, head uint64) (string, error) {
+	confirmations := config.C.GetUint64("confirmations")
+	if head < confirmations {
+		return "", nil
+	}
+	lag, evtName, err := event_logger.MaxLag(ctx, head-confirmations)
+	if err != nil {
+		return "", err
+	}
+	maxLag := config.C.GetUint64("readyMaxLag")
+	if maxLag == 0 {
+		maxLag = defaultReadyMaxLag
+	}
+	detail := fmt.Sprintf("%d blocks", lag)
+	if lag > maxLag {
+		return detail, errors.New(fmt.Sprintf("%s is %d blocks behind, more than %d", evtName, lag, maxLag))
+	}
+	return detail, nil
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"boralabs/config"
+	"boralabs/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMetricsPort = "9090"
+
+// Probes Engine of the processes without the REST API, e.g. the index subcommand,
+// it only serves the Prometheus metrics and the liveness probe.
+func Probes() *gin.Engine {
+	e := gin.New()
+	e.Use(gin.Recovery())
+	e.GET("livez", livez)
+	e.GET("metrics", metrics.Handler())
+	return e
+}
+
+// MetricsPort Port the Probes engine listens on.
+func MetricsPort() string {
+	if p := config.C.GetString("metricsPort"); p != "" {
+		return p
+	}
+	return defaultMetricsPort
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"boralabs/config"
+	"boralabs/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimit      = 5
+	defaultRateLimitBurst = 10
+	rateLimiterIdleTTL    = 3 * time.Minute // a client idle for this long starts over with a full bucket
+)
+
+// RateLimiter Define RateLimiter struct, each client IP gets its own bucket
+type RateLimiter struct {
+	r         rate.Limit
+	b         int
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter Initialize RateLimiter with NewRateLimiter function
+func NewRateLimiter(r rate.Limit, b int) *RateLimiter {
+	return &RateLimiter{
+		r:         r,
+		b:         b,
+		clients:   make(map[string]*clientLimiter),
+		lastSweep: time.Now(),
+	}
+}
+
+// NewRateLimiterFromConfig RateLimiter with the rateLimit.rps and rateLimit.burst config values.
+func NewRateLimiterFromConfig() *RateLimiter {
+	r := config.C.GetFloat64("rateLimit.rps")
+	if r <= 0 {
+		r = defaultRateLimit
+	}
+	b := config.C.GetInt("rateLimit.burst")
+	if b <= 0 {
+		b = defaultRateLimitBurst
+	}
+	return NewRateLimiter(rate.Limit(r), b)
+}
+
+// allow Take a token from the bucket of the client, the buckets of idle clients are dropped once a minute.
+func (rl *RateLimiter) allow(clientIP string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(rl.lastSweep) > time.Minute {
+		for ip, c := range rl.clients {
+			if now.Sub(c.lastSeen) > rateLimiterIdleTTL {
+				delete(rl.clients, ip)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	c, ok := rl.clients[clientIP]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(rl.r, rl.b)}
+		rl.clients[clientIP] = c
+	}
+	c.lastSeen = now
+	return c.limiter.Allow()
+}
+
+// Middleware Implement Rate Limiting middleware with Middleware function
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rl.allow(c.ClientIP()) {
+			metrics.RateLimited.Inc()
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"boralabs/config"
+	"boralabs/internal/event_logger"
+	"boralabs/internal/model"
+	mongoDb "boralabs/pkg/datastore/mongodb"
+	"boralabs/pkg/router/rest"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+	"strings"
+)
+
+type AdminV1 struct {
+	rest.Response
+}
+
+type requestFailedLogs struct {
+	Page   int64  `form:"page" json:"page"`
+	Source string `form:"source" json:"source"`
+}
+
+func (a AdminV1) routes(group *gin.RouterGroup) {
+	admin := group.Group("admin", adminAuth)
+	{
+		admin.GET("failed-logs", a.findFailedLogs)
+		admin.POST("failed-logs/:id/retry", a.retryFailedLog)
+		admin.DELETE("failed-logs/:id", a.discardFailedLog)
+	}
+}
+
+// adminAuth Require the adminToken of the config as bearer token. The admin API is disabled without one.
+func adminAuth(c *gin.Context) {
+	token := config.C.GetString("adminToken")
+	bearer, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" || !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"code":    http.StatusUnauthorized,
+			"message": http.StatusText(http.StatusUnauthorized),
+			"data":    nil,
+		})
+		return
+	}
+	c.Next()
+}
+
+// findFailedLogs Logs waiting in the dead-letter queue, latest failure first.
+func (a AdminV1) findFailedLogs(c *gin.Context) {
+	a.Context = c
+	req := requestFailedLogs{Page: 1}
+	if err := c.BindQuery(&req); err != nil {
+		a.Code = http.StatusBadRequest
+		a.JsonError(err)
+		return
+	}
+	filter := bson.D{}
+	if req.Source != "" {
+		filter = append(filter, bson.E{Key: "source", Value: req.Source})
+	}
+
+	a.BaseResponse.Paginator = mongoDb.NewPaginator()
+	a.BaseResponse.Paginator.Page = req.Page
+	cursor, err := a.BaseResponse.Paginator.Calculate(mongoDb.CollectionFailedLogs, filter, bson.D{{Key: "updated_at", Value: -1}})
+	if err != nil {
+		a.JsonError(err)
+		return
+	}
+	failedLogs := make([]model.FailedLog, 0)
+	if err = cursor.All(context.Background(), &failedLogs); err != nil {
+		a.JsonError(err)
+		return
+	}
+
+	a.BaseResponse.Data = gin.H{
+		"items": failedLogs,
+	}
+	a.BaseResponse.IsPaging = true
+	a.Json()
+}
+
+// retryFailedLog Handle the failed log right away, regardless of its attempts and backoff.
+func (a AdminV1) retryFailedLog(c *gin.Context) {
+	a.Context = c
+	failed, ok := a.failedLog(c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := event_logger.RetryFailedLog(c.Request.Context(), failed); err != nil {
+		a.Code = http.StatusConflict
+		a.JsonError(err)
+		return
+	}
+	a.Json()
+}
+
+// discardFailedLog Drop the failed log from the dead-letter queue without handling it.
+func (a AdminV1) discardFailedLog(c *gin.Context) {
+	a.Context = c
+	failed, ok := a.failedLog(c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := mongoDb.DeleteFailedLog(c.Request.Context(), failed.ID); err != nil {
+		a.JsonError(err)
+		return
+	}
+	a.Json()
+}
+
+func (a AdminV1) failedLog(id string) (failed model.FailedLog, ok bool) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		a.Code = http.StatusBadRequest
+		a.JsonError(err)
+		return
+	}
+	if failed, err = mongoDb.GetFailedLog(a.Context.Request.Context(), objectID); err != nil {
+		if errors.Is(err, mongo2.ErrNoDocuments) {
+			a.Code = http.StatusNotFound
+		}
+		a.JsonError(err)
+		return
+	}
+	return failed, true
+}
@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"boralabs/internal/chain"
+	"boralabs/internal/model"
+	mongoDb "boralabs/pkg/datastore/mongodb"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/router/rest"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"net/http"
+)
+
+type DelegateV1 struct {
+	rest.Response
+}
+
+type requestPage struct {
+	Page int64 `form:"page" json:"page"`
+}
+
+func (d DelegateV1) routes(group *gin.RouterGroup) {
+	delegates := group.Group("delegates")
+	{
+		delegates.GET("", d.findAll)
+		delegates.GET(":address", d.find)
+	}
+	accounts := group.Group("accounts")
+	{
+		accounts.GET(":address/delegation", d.findDelegation)
+	}
+}
+
+// findAll Delegates ordered by their latest voting power.
+func (d DelegateV1) findAll(c *gin.Context) {
+	d.Context = c
+	req := requestPage{Page: 1}
+	if err := c.BindQuery(&req); err != nil {
+		d.Code = http.StatusBadRequest
+		d.JsonError(err)
+		return
+	}
+
+	pipeline := mongo2.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$delegate"},
+			{Key: "voting_power", Value: bson.D{{Key: "$first", Value: "$new_balance"}}},
+			{Key: "block_number", Value: bson.D{{Key: "$first", Value: "$block_number"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "voting_power", Value: bson.D{{Key: "$ne", Value: "0"}}}}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: chain.NameDelegations},
+			{Key: "localField", Value: "_id"},
+			{Key: "foreignField", Value: "delegate"},
+			{Key: "as", Value: "delegators"},
+		}}},
+		{{Key: "$addFields", Value: bson.D{
+			{Key: "delegator_count", Value: bson.D{{Key: "$size", Value: "$delegators"}}},
+			{Key: "voting_power_sort", Value: bson.D{{Key: "$toDecimal", Value: "$voting_power"}}},
+		}}},
+		{{Key: "$project", Value: bson.D{{Key: "delegators", Value: 0}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "voting_power_sort", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	d.BaseResponse.Paginator = mongoDb.NewPaginator()
+	d.BaseResponse.Paginator.Page = req.Page
+	cursor, err := d.BaseResponse.Paginator.CalculateAggregate(chain.NameDelegateVotesHistory, pipeline)
+	if err != nil {
+		d.JsonError(err)
+		return
+	}
+
+	delegates := make([]model.Delegate, 0)
+	if err = cursor.All(context.Background(), &delegates); err != nil {
+		d.JsonError(err)
+		return
+	}
+
+	d.BaseResponse.Data = gin.H{
+		"items": delegates,
+	}
+	d.BaseResponse.IsPaging = true
+	d.Json()
+}
+
+// find Voting power history and delegators of a delegate.
+func (d DelegateV1) find(c *gin.Context) {
+	d.Context = c
+	address, ok := parseAddress(c.Param("address"))
+	if !ok {
+		d.Code = http.StatusBadRequest
+		d.JsonError(errors.New(boraLabsErr.InvalidAddress))
+		return
+	}
+	req := requestPage{Page: 1}
+	if err := c.BindQuery(&req); err != nil {
+		d.Code = http.StatusBadRequest
+		d.JsonError(err)
+		return
+	}
+
+	// delegators
+	cursor, err := mongoDb.DB.Collection(chain.NameDelegations).Find(context.Background(), bson.D{
+		{Key: "delegate", Value: address},
+	}, options.Find().SetSort(bson.D{{Key: "block_number", Value: -1}}))
+	if err != nil {
+		d.JsonError(err)
+		return
+	}
+	delegators := make([]model.Delegation, 0)
+	if err = cursor.All(context.Background(), &delegators); err != nil {
+		d.JsonError(err)
+		return
+	}
+
+	// voting power history
+	d.BaseResponse.Paginator = mongoDb.NewPaginator()
+	d.BaseResponse.Paginator.Page = req.Page
+	cursor, err = d.BaseResponse.Paginator.Calculate(chain.NameDelegateVotesHistory, bson.D{
+		{Key: "delegate", Value: address},
+	}, bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}})
+	if err != nil {
+		d.JsonError(err)
+		return
+	}
+	history := make([]model.DelegateVotes, 0)
+	if err = cursor.All(context.Background(), &history); err != nil {
+		d.JsonError(err)
+		return
+	}
+
+	if len(delegators) == 0 && d.BaseResponse.Paginator.Total == 0 {
+		d.Code = http.StatusNotFound
+		d.JsonError(mongo2.ErrNoDocuments)
+		return
+	}
+
+	// latest voting power
+	votingPower := "0"
+	var latest model.DelegateVotes
+	err = mongoDb.DB.Collection(chain.NameDelegateVotesHistory).FindOne(context.Background(), bson.D{
+		{Key: "delegate", Value: address},
+	}, options.FindOne().SetSort(bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}})).Decode(&latest)
+	if err == nil {
+		votingPower = latest.NewBalance
+	}
+
+	d.BaseResponse.Data = gin.H{
+		"delegate":     address,
+		"voting_power": votingPower,
+		"delegators":   delegators,
+		"items":        history,
+	}
+	d.BaseResponse.IsPaging = true
+	d.Json()
+}
+
+// findDelegation Current delegate of an account.
+func (d DelegateV1) findDelegation(c *gin.Context) {
+	d.Context = c
+	address, ok := parseAddress(c.Param("address"))
+	if !ok {
+		d.Code = http.StatusBadRequest
+		d.JsonError(errors.New(boraLabsErr.InvalidAddress))
+		return
+	}
+
+	var delegation model.Delegation
+	err := mongoDb.DB.Collection(chain.NameDelegations).FindOne(context.Background(), bson.D{
+		{Key: "delegator", Value: address},
+	}).Decode(&delegation)
+	if err != nil {
+		if errors.Is(err, mongo2.ErrNoDocuments) {
+			d.Code = http.StatusNotFound
+		}
+		d.JsonError(err)
+		return
+	}
+
+	d.BaseResponse.Data = delegation
+	d.Json()
+}
+
+// parseAddress Validate the address and convert it to the checksum format stored by the collector.
+func parseAddress(address string) (string, bool) {
+	if !common.IsHexAddress(address) {
+		return "", false
+	}
+	return common.HexToAddress(address).String(), true
+}
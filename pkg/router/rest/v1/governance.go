@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"boralabs/internal/chain"
+	"boralabs/internal/model"
+	mongoDb "boralabs/pkg/datastore/mongodb"
+	boraLabsErr "boralabs/pkg/error"
+	"boralabs/pkg/router/rest"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	mongo2 "go.mongodb.org/mongo-driver/mongo"
+	"net/http"
+)
+
+type GovernanceV1 struct {
+	rest.Response
+}
+
+type requestGovernanceHistory struct {
+	Parameter  string `form:"parameter" json:"parameter"`
+	ProposalId string `form:"proposal_id" json:"proposal_id"`
+	Page       int64  `form:"page" json:"page"`
+}
+
+func (g GovernanceV1) routes(group *gin.RouterGroup) {
+	group = group.Group("governance")
+	{
+		group.GET("", g.find)
+		group.GET("history", g.findHistory)
+	}
+}
+
+// find Current governor and token settings.
+func (g GovernanceV1) find(c *gin.Context) {
+	g.Context = c
+	settings, err := chain.GetGovernanceSettings(c.Request.Context())
+	if err != nil {
+		g.Code = http.StatusBadGateway
+		g.JsonError(err)
+		return
+	}
+
+	g.BaseResponse.Data = settings
+	g.Json()
+}
+
+// findHistory Governor parameter changes, newest first.
+// With proposal_id only the changes applied up to the block the proposal was created in are listed.
+func (g GovernanceV1) findHistory(c *gin.Context) {
+	g.Context = c
+	req := requestGovernanceHistory{Page: 1}
+	if err := c.BindQuery(&req); err != nil {
+		g.Code = http.StatusBadRequest
+		g.JsonError(err)
+		return
+	}
+
+	filter := bson.D{}
+	if req.Parameter != "" {
+		filter = append(filter, bson.E{Key: "parameter", Value: req.Parameter})
+	}
+	if req.ProposalId != "" {
+		var proposal model.Proposal
+		err := mongoDb.DB.Collection(chain.NameProposals).FindOne(context.Background(), bson.D{
+			{Key: "proposal_id", Value: req.ProposalId},
+		}).Decode(&proposal)
+		if err != nil {
+			if errors.Is(err, mongo2.ErrNoDocuments) {
+				g.Code = http.StatusNotFound
+			}
+			g.JsonError(err)
+			return
+		}
+		if proposal.BlockNumber == 0 { // submitted through the API, the block it was created in is not known yet
+			g.Code = http.StatusNotFound
+			g.JsonError(errors.New(fmt.Sprintf(boraLabsErr.ProposalNotIndexed, req.ProposalId)))
+			return
+		}
+		filter = append(filter, bson.E{Key: "block_number", Value: bson.D{{Key: "$lte", Value: proposal.BlockNumber}}})
+	}
+
+	g.BaseResponse.Paginator = mongoDb.NewPaginator()
+	g.BaseResponse.Paginator.Page = req.Page
+	cursor, err := g.BaseResponse.Paginator.Calculate(chain.NameGovernanceParameterChanges, filter,
+		bson.D{{Key: "block_number", Value: -1}, {Key: "log_index", Value: -1}})
+	if err != nil {
+		g.JsonError(err)
+		return
+	}
+
+	changes := make([]model.GovernanceParameterChange, 0)
+	if err = cursor.All(context.Background(), &changes); err != nil {
+		g.JsonError(err)
+		return
+	}
+
+	g.BaseResponse.Data = gin.H{
+		"items": changes,
+	}
+	g.BaseResponse.IsPaging = true
+	g.Json()
+}
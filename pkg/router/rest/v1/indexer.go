@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"boralabs/internal/event_logger"
+	"boralabs/pkg/router/rest"
+	"github.com/gin-gonic/gin"
+)
+
+type IndexerV1 struct {
+	rest.Response
+}
+
+func (i IndexerV1) routes(group *gin.RouterGroup) {
+	group = group.Group("indexer")
+	{
+		group.GET("status", i.findStatus)
+	}
+}
+
+// findStatus Progress, last runs and failed logs of each collected event, to tell a quiet chain from a stuck indexer.
+func (i IndexerV1) findStatus(c *gin.Context) {
+	i.Context = c
+	statuses, err := event_logger.Status(c.Request.Context())
+	if err != nil {
+		i.JsonError(err)
+		return
+	}
+
+	i.BaseResponse.Data = gin.H{
+		"items": statuses,
+	}
+	i.Json()
+}
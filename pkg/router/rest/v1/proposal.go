@@ -2,14 +2,11 @@ package v1
 
 import (
 	"boralabs/config"
-	"boralabs/internal/chain"
 	"boralabs/internal/event_logger"
 	"boralabs/internal/model"
 	mongoDb "boralabs/pkg/datastore/mongodb"
 	boraLabsErr "boralabs/pkg/error"
-	"boralabs/pkg/notification"
 	"boralabs/pkg/router/rest"
-	"boralabs/pkg/util"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -18,7 +15,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	mongo2 "go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"io"
 	"log"
 	"net/http"
@@ -145,16 +141,10 @@ func (p ProposalV1) create(c *gin.Context) {
 		return
 	}
 
-	blockNumber := uint64(0)
-	lProposal, err := latestProposal(req.ID)
-	if err == nil {
-		blockNumber = lProposal.BlockNumber
-	}
-
 	// db update
 	updateStartTime := time.Now() // Record start time
-	updateRes := updater.Update(blockNumber)
-	if updateRes != true {
+	attached, err := updater.Update(c.Request.Context())
+	if err != nil {
 		p.Code = http.StatusInternalServerError
 		p.BaseResponse.Message = boraLabsErr.FailedUpdateLogData
 		p.JsonError(errors.New("error retrieving blockchain event data for proposal"))
@@ -165,6 +155,11 @@ func (p ProposalV1) create(c *gin.Context) {
 
 	// response
 	p.Code = http.StatusCreated
+	if !attached {
+		// the log is not confirmations deep yet, the collector attaches the chain data later
+		p.Code = http.StatusAccepted
+		p.BaseResponse.Message = fmt.Sprintf(boraLabsErr.ProposalNotConfirmed, req.ProposalID)
+	}
 	p.BaseResponse.Data = gin.H{
 		"proposals": []model.Proposal{req},
 	}
@@ -172,25 +167,6 @@ func (p ProposalV1) create(c *gin.Context) {
 	return
 }
 
-func latestProposal(ID uint64) (result model.Proposal, err error) {
-	// Set search criteria and sorting criteria
-	filter := bson.M{
-		"id":           bson.M{"$lt": ID}, // Condition where the value is less than the value in column id
-		"block_number": bson.M{"$ne": 0},  // Condition where the value in column block_number is not zero
-	}
-	findOptions := options.FindOne().SetSort(bson.D{{"id", -1}}) // Sort in descending order based on column A
-
-	// Single document search
-	coll := mongoDb.DB.Collection(mongoCollectionName)
-	err = coll.FindOne(context.TODO(), filter, findOptions).Decode(&result)
-	if err != nil {
-		if errors.Is(err, mongo2.ErrNoDocuments) {
-			log.Println("No document found")
-		}
-	}
-	return
-}
-
 func (p ProposalV1) find(c *gin.Context) {
 	p.Context = c
 	proposalId := c.Param("id")
@@ -210,9 +186,6 @@ func (p ProposalV1) find(c *gin.Context) {
 		return
 	}
 
-	// update proposal state
-	go updateState([]model.Proposal{proposal})
-
 	p.BaseResponse.Data = proposal
 	p.Json()
 }
@@ -245,9 +218,6 @@ func (p ProposalV1) findAll(c *gin.Context) {
 		return
 	}
 
-	// update proposal state
-	go updateState(proposals)
-
 	p.BaseResponse.Data = proposals
 	p.BaseResponse.IsPaging = true
 	p.Json()
@@ -261,30 +231,3 @@ func (p ProposalV1) findLatestId(c *gin.Context) {
 	p.BaseResponse.Data = result
 	p.Json()
 }
-
-func updateState(p []model.Proposal) {
-	var lastProposalId uint64
-	defer func() {
-		if err := recover(); err != nil {
-			notification.SendAll(fmt.Sprintf("Panic Proposal [%d] AppendSave %v", lastProposalId, err))
-		}
-	}()
-	// proposals update
-	log.Println("=== Start update Proposals State ===")
-	for _, proposal := range p {
-		lastProposalId = proposal.ID
-		_, err := mongoDb.DB.Collection(mongoCollectionName).UpdateOne(
-			context.Background(),
-			bson.D{{Key: "proposal_id", Value: proposal.ProposalID}},
-			bson.D{
-				{"$set", bson.D{
-					{Key: "state", Value: chain.CalcProposalState(proposal.StartDate, proposal.EndDate)},
-				}},
-			}, nil)
-		if err != nil {
-			util.ErrorLog(errors.New(fmt.Sprintf(boraLabsErr.FailedSaveLogData, err)))
-		}
-		log.Printf("=== Updated Proposals State [%s] ===\n", proposal.ProposalID)
-	}
-	log.Println("=== End update Proposals State ===")
-}
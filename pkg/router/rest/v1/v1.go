@@ -16,4 +16,8 @@ type REST struct{}
 // RoutesV1 REST API Version 1
 func (r REST) RoutesV1(g *gin.RouterGroup) {
 	ProposalV1{}.routes(g) // proposal and vote
+	DelegateV1{}.routes(g) // delegates and account delegation
+	GovernanceV1{}.routes(g)
+	AdminV1{}.routes(g) // dead-letter queue
+	IndexerV1{}.routes(g)
 }
@@ -96,19 +96,8 @@ func (v VoteV1) create(c *gin.Context) {
 	}
 
 	// db update
-	var proposal model.Proposal
-	err := mongoDb.DB.Collection(mongoCollectionName).FindOne(context.Background(), bson.D{
-		{"proposal_id", req.ProposalId},
-	}).Decode(&proposal)
-
-	blockNumber := uint64(0)
-	lProposal, err := latestProposal(proposal.ID)
-	if err == nil {
-		blockNumber = lProposal.BlockNumber
-	}
-
 	updater := event_logger.VoteUpdater{VoteCast: &req}
-	updater.Update(blockNumber)
+	updater.Update(c.Request.Context())
 
 	v.Code = 200
 	v.BaseResponse.Data = gin.H{
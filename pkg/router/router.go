@@ -1,6 +1,7 @@
 package router
 
 import (
+	"boralabs/pkg/metrics"
 	v1 "boralabs/pkg/router/rest/v1"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -13,6 +14,7 @@ var E *gin.Engine
 func init() {
 	E = gin.New()
 	E.RemoveExtraSlash = true
+	E.Use(metrics.Middleware) // outside the recovery so the recovered panics are observed as 500
 	E.Use(gin.Recovery())
 	E.Use(gin.Logger())
 	E.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
@@ -37,9 +39,15 @@ func init() {
 		c.Status(http.StatusOK)
 		return
 	})
+	E.GET("livez", livez)
+	E.GET("readyz", readyz)
 
-	// REST API V1 Routes
-	v1Group := E.Group("")
+	// Prometheus metrics
+	E.GET("metrics", metrics.Handler())
+
+	// REST API V1 Routes, rate limited per client IP. gin only applies middlewares to the routes registered after them,
+	// so the limiter is part of the group rather than added to the engine once the routes exist.
+	v1Group := E.Group("", NewRateLimiterFromConfig().Middleware())
 	rest := v1.REST{}
 	rest.RoutesV1(v1Group)
 }